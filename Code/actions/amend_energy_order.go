@@ -0,0 +1,164 @@
+package actions
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*AmendEnergyOrder)(nil)
+
+type AmendEnergyOrder struct {
+	// [Order] is the OrderID you wish to amend.
+	Order ids.ID `json:"order"`
+
+	// [Out] is the asset locked up in the order. We need to provide this to
+	// populate [StateKeys].
+	Out ids.ID `json:"out"`
+
+	// [InTick] and [OutTick] replace the price of the order.
+	InTick  uint64 `json:"inTick"`
+	OutTick uint64 `json:"outTick"`
+
+	// [Supply] replaces the remaining supply of the order. The difference is
+	// locked up or refunded from the balance of the owner.
+	Supply uint64 `json:"supply"`
+}
+
+// KeepsPriority returns true if an order listed at [inTick]/[outTick] with
+// [remaining] supply keeps its place in the book when amended to
+// [newInTick]/[newOutTick] with [newRemaining]. It only does if the rate is
+// unchanged and the supply does not grow, so an order can never get ahead of
+// orders that were at its new price or size first.
+func KeepsPriority(
+	inTick uint64,
+	outTick uint64,
+	remaining uint64,
+	newInTick uint64,
+	newOutTick uint64,
+	newRemaining uint64,
+) bool {
+	if newRemaining > remaining {
+		return false
+	}
+	hi, lo := bits.Mul64(inTick, newOutTick)
+	newHi, newLo := bits.Mul64(newInTick, outTick)
+	return hi == newHi && lo == newLo
+}
+
+func (a *AmendEnergyOrder) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	actor := auth.GetActor(rauth)
+	return append([][]byte{
+		storage.PrefixEnergyOrderKey(a.Order),
+		storage.PrefixBalanceKey(actor, a.Out),
+		storage.PrefixRoleKey(actor),
+	}, frozenKeys(a.Out)...)
+}
+
+func (a *AmendEnergyOrder) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := a.MaxUnits(r) // max units == units
+	if a.InTick == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInTickZero}, nil
+	}
+	if a.OutTick == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOutTickZero}, nil
+	}
+	if a.Supply == 0 {
+		// Use [CloseEnergyOrder] to remove an order
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSupplyZero}, nil
+	}
+	if a.Supply%a.OutTick != 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSupplyMisaligned}, nil
+	}
+	exists, side, in, _, out, _, remaining, owner, expiry, allOrNone, minFill, err := storage.GetEnergyOrder(ctx, db, a.Order)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOrderMissing}, nil
+	}
+	if owner != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if out != a.Out {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOut}, nil
+	}
+	if Expired(expiry, t) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOrderExpired}, nil
+	}
+	if !allOrNone && a.Supply < minFill {
+		// The order could then only be filled whole
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSupplyBelowMinFill}, nil
+	}
+	switch {
+	case a.Supply > remaining:
+		// Locking up more is listing more, so it needs the same permissions
+		// as [CreateEnergyOrder]
+		if ok, err := hasRole(ctx, r, db, actor, marketRoles); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		} else if !ok {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+		}
+		if failure := checkNotFrozen(ctx, db, a.Out); failure != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+		}
+		if err := storage.SubBalance(ctx, db, actor, a.Out, a.Supply-remaining); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	case a.Supply < remaining:
+		if err := storage.AddBalance(ctx, db, actor, a.Out, remaining-a.Supply); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+	if err := storage.SetEnergyOrder(
+		ctx, db, a.Order, side, in, a.InTick,
+		out, a.OutTick, a.Supply, owner, expiry,
+		allOrNone, minFill,
+	); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*AmendEnergyOrder) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen*2 + consts.Uint64Len*3
+}
+
+func (a *AmendEnergyOrder) Marshal(p *codec.Packer) {
+	p.PackID(a.Order)
+	p.PackID(a.Out)
+	p.PackUint64(a.InTick)
+	p.PackUint64(a.OutTick)
+	p.PackUint64(a.Supply)
+}
+
+func UnmarshalAmendEnergyOrder(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var amend AmendEnergyOrder
+	p.UnpackID(true, &amend.Order)
+	p.UnpackID(false, &amend.Out) // empty ID is the native asset
+	amend.InTick = p.UnpackUint64(true)
+	amend.OutTick = p.UnpackUint64(true)
+	amend.Supply = p.UnpackUint64(true)
+	return &amend, p.Err()
+}
+
+func (*AmendEnergyOrder) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,161 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+// AssetMetadataVersion is the version of the [AssetMetadata] schema. It is
+// the first byte of the encoded metadata so the schema can be extended.
+const AssetMetadataVersion = 1
+
+// EnergyUnit is the unit one base unit of an energy asset is denominated in,
+// before [AssetMetadata.Decimals] are applied.
+type EnergyUnit uint8
+
+const (
+	UnitWh EnergyUnit = iota
+	UnitKWh
+	UnitMWh
+)
+
+var unitNames = []string{"Wh", "kWh", "MWh"}
+
+func (u EnergyUnit) String() string {
+	if int(u) >= len(unitNames) {
+		return fmt.Sprintf("unit(%d)", u)
+	}
+	return unitNames[u]
+}
+
+func (u EnergyUnit) MarshalText() ([]byte, error) {
+	if int(u) >= len(unitNames) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidUnit, u)
+	}
+	return []byte(unitNames[u]), nil
+}
+
+func (u *EnergyUnit) UnmarshalText(b []byte) error {
+	for i, name := range unitNames {
+		if name == string(b) {
+			*u = EnergyUnit(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidUnit, b)
+}
+
+// AssetMetadata describes an energy asset so wallets can display it. It is
+// stored encoded in the asset record.
+type AssetMetadata struct {
+	// [Symbol] is the ticker shown by wallets, such as SOLAR.
+	Symbol string `json:"symbol"`
+
+	// [Unit] and [Decimals] give the amount of energy in one base unit of
+	// the asset: 10^-[Decimals] [Unit].
+	Unit     EnergyUnit `json:"unit"`
+	Decimals uint8      `json:"decimals"`
+
+	// [Source] is the technology the energy is produced with, such as solar.
+	Source string `json:"source"`
+
+	// [Region] is the grid region the energy is delivered in.
+	Region string `json:"region"`
+
+	// [Description] is optional free text.
+	Description string `json:"description"`
+}
+
+// Verify returns an error if [m] can't be stored.
+func (m *AssetMetadata) Verify() error {
+	if err := VerifySymbol(m.Symbol); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	if m.Unit > UnitMWh {
+		return fmt.Errorf("%w: %d", ErrInvalidUnit, m.Unit)
+	}
+	if m.Decimals > MaxAssetDecimals {
+		return fmt.Errorf("%w: decimals must be at most %d", ErrInvalidMetadata, MaxAssetDecimals)
+	}
+	if len(m.Source) == 0 || len(m.Source) > MaxCertificateFieldSize {
+		return fmt.Errorf("%w: source must be 1-%d bytes", ErrInvalidMetadata, MaxCertificateFieldSize)
+	}
+	if len(m.Region) > MaxCertificateFieldSize {
+		return fmt.Errorf("%w: region must be at most %d bytes", ErrInvalidMetadata, MaxCertificateFieldSize)
+	}
+	if len(m.Description) > MaxDescriptionSize {
+		return fmt.Errorf("%w: description must be at most %d bytes", ErrInvalidMetadata, MaxDescriptionSize)
+	}
+	return nil
+}
+
+// VerifySymbol returns an error if [symbol] is not 1-[MaxSymbolSize]
+// uppercase letters and digits.
+func VerifySymbol(symbol string) error {
+	if len(symbol) == 0 || len(symbol) > MaxSymbolSize {
+		return fmt.Errorf("%w: symbol must be 1-%d characters", ErrInvalidSymbol, MaxSymbolSize)
+	}
+	for _, c := range symbol {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("%w: symbol must be uppercase letters and digits", ErrInvalidSymbol)
+		}
+	}
+	return nil
+}
+
+func (m *AssetMetadata) Marshal() ([]byte, error) {
+	if err := m.Verify(); err != nil {
+		return nil, err
+	}
+	p := codec.NewWriter(MaxMetadataSize)
+	p.PackByte(AssetMetadataVersion)
+	p.PackString(m.Symbol)
+	p.PackByte(byte(m.Unit))
+	p.PackByte(m.Decimals)
+	p.PackString(m.Source)
+	p.PackString(m.Region)
+	p.PackString(m.Description)
+	return p.Bytes(), p.Err()
+}
+
+// UnmarshalAssetMetadata decodes and verifies metadata written by
+// [AssetMetadata.Marshal].
+func UnmarshalAssetMetadata(b []byte) (*AssetMetadata, error) {
+	p := codec.NewReader(b, MaxMetadataSize)
+	if version := p.UnpackByte(); p.Err() == nil && version != AssetMetadataVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedMetadataVersion, version)
+	}
+	var m AssetMetadata
+	m.Symbol = p.UnpackString(true)
+	m.Unit = EnergyUnit(p.UnpackByte())
+	m.Decimals = p.UnpackByte()
+	m.Source = p.UnpackString(true)
+	m.Region = p.UnpackString(false)
+	m.Description = p.UnpackString(false)
+	if err := p.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	if !p.Empty() {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrInvalidMetadata)
+	}
+	if err := m.Verify(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// parseAssetMetadata returns the canonical encoding of [b]. If [b] is not
+// valid metadata, [failure] is set to the output of the failed action.
+func parseAssetMetadata(b []byte) (metadata []byte, failure []byte) {
+	m, err := UnmarshalAssetMetadata(b)
+	if err != nil {
+		return nil, OutputInvalidMetadata
+	}
+	metadata, err = m.Marshal()
+	if err != nil {
+		return nil, utils.ErrBytes(err)
+	}
+	return metadata, nil
+}
@@ -43,7 +43,7 @@ func (c *CloseEnergyOrder) Execute(
 ) (*chain.Result, error) {
 	actor := auth.GetActor(rauth)
 	unitsUsed := c.MaxUnits(r) // max units == units
-	exists, _, _, out, _, remaining, owner, err := storage.GetEnergyOrder(ctx, db, c.Order)
+	exists, _, _, _, out, _, remaining, owner, _, _, _, err := storage.GetEnergyOrder(ctx, db, c.Order)
 	if err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
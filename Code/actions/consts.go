@@ -1,3 +1,23 @@
 package actions
 
-const MaxMetadataSize = 256
+const (
+	MaxMetadataSize = 256
+
+	// MaxSymbolSize, MaxAssetDecimals and MaxDescriptionSize bound the fields
+	// of [AssetMetadata] so its encoding fits in [MaxMetadataSize].
+	MaxSymbolSize      = 8
+	MaxAssetDecimals   = 9
+	MaxDescriptionSize = 96
+
+	// MaxCertificateFieldSize bounds the technology, region and beneficiary
+	// recorded on a certificate.
+	MaxCertificateFieldSize = 64
+
+	// MaxCertificatesPerTx bounds the certificates a single reading can
+	// issue, as each one is a separate state key.
+	MaxCertificatesPerTx = 32
+
+	// MaxOrderMatches bounds the resting orders a single action can fill, as
+	// each one adds state keys.
+	MaxOrderMatches = 16
+)
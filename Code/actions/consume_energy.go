@@ -51,7 +51,7 @@ func (b *ConsumeEnergy) Execute(
 	if err := storage.SubBalance(ctx, db, actor, b.Asset, b.Value); err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
-	exists, metadata, supply, owner, warp, err := storage.GetAsset(ctx, db, b.Asset)
+	exists, metadata, supply, owner, warp, frozen, err := storage.GetAsset(ctx, db, b.Asset)
 	if err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
@@ -62,7 +62,7 @@ func (b *ConsumeEnergy) Execute(
 	if err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
-	if err := storage.SetAsset(ctx, db, b.Asset, metadata, newSupply, owner, warp); err != nil {
+	if err := storage.SetAsset(ctx, db, b.Asset, metadata, newSupply, owner, warp, frozen); err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
 	return &chain.Result{Success: true, Units: unitsUsed}, nil
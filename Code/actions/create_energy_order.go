@@ -3,6 +3,7 @@ package actions
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
@@ -11,41 +12,82 @@ import (
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/utils"
 	"github.com/bbehrman10/energyavavm/auth"
+	nconsts "github.com/bbehrman10/energyavavm/consts"
 	"github.com/bbehrman10/energyavavm/storage"
 )
 
 var _ chain.Action = (*CreateEnergyOrder)(nil)
 
+const (
+	// SideAsk orders lock up energy and are filled by buyers paying [In].
+	SideAsk uint8 = iota
+
+	// SideBid orders lock up currency and are filled by sellers delivering
+	// energy as [In].
+	SideBid
+)
+
+// OrderSide returns the side of an order that receives [in] for [out]. Every
+// pair is quoted in the native asset, or in the asset with the lower ID if
+// neither is native, and the orders that lock up the quote asset are bids.
+func OrderSide(in ids.ID, out ids.ID) uint8 {
+	if out == ids.Empty || (in != ids.Empty && out.Less(in)) {
+		return SideBid
+	}
+	return SideAsk
+}
+
 type CreateEnergyOrder struct {
-	// In represents the energy in kilowatt-hours (kWh) the seller wants to sell.
+	// Side is either [SideAsk] or [SideBid]. Both sides are filled the same
+	// way, it only determines where the order is listed in the book. It must
+	// be the [OrderSide] of [In] and [Out].
+	Side uint8 `json:"side"`
+
+	// In is the asset the order owner receives when the order is filled. This
+	// is the currency for an ask and the energy for a bid.
 	In ids.ID `json:"in"`
 
-	// InTick represents the amount of energy in kWh per unit of currency.
+	// InTick is the amount of [In] the owner receives per tick.
 	InTick uint64 `json:"inTick"`
 
-	// Out represents the currency the seller accepts in exchange for energy.
+	// Out is the asset the order owner locks up. This is the energy for an
+	// ask and the currency for a bid.
 	Out ids.ID `json:"out"`
 
-	// OutTick represents the amount of currency the seller receives per unit of energy.
+	// OutTick is the amount of [Out] the owner gives up per tick.
 	OutTick uint64 `json:"outTick"`
 
-	// Supply represents the total amount of energy in kWh the seller is willing to sell.
+	// Supply is the total amount of [Out] locked up in the order.
 	Supply uint64 `json:"supply"`
+
+	// Expiry is the unix timestamp (in seconds) after which the order can no
+	// longer be filled and anyone can reclaim it for the seller. 0 means the
+	// order never expires.
+	Expiry int64 `json:"expiry"`
+
+	// AllOrNone orders can only be filled by taking all of the remaining
+	// supply at once.
+	AllOrNone bool `json:"allOrNone"`
+
+	// MinFill is the least amount of [Out] a single fill must take, unless it
+	// takes all of the remaining supply.
+	MinFill uint64 `json:"minFill"`
 }
 
 func (c *CreateEnergyOrder) StateKeys(rauth chain.Auth, txID ids.ID) [][]byte {
 	actor := auth.GetActor(rauth)
-	return [][]byte{
+	return append([][]byte{
 		storage.PrefixBalanceKey(actor, c.Out),
 		storage.PrefixEnergyOrderKey(txID),
-	}
+		storage.PrefixRoleKey(actor),
+	}, frozenKeys(c.In, c.Out)...)
 }
 
 func (c *CreateEnergyOrder) Execute(
 	ctx context.Context,
 	r chain.Rules,
 	db chain.Database,
-	_ int64,
+	t int64,
 	rauth chain.Auth,
 	txID ids.ID,
 	_ bool,
@@ -55,6 +97,9 @@ func (c *CreateEnergyOrder) Execute(
 	if c.In == c.Out {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSameInOut}, nil
 	}
+	if c.Side != OrderSide(c.In, c.Out) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSideMismatch}, nil
+	}
 	if c.InTick == 0 {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInTickZero}, nil
 	}
@@ -67,41 +112,133 @@ func (c *CreateEnergyOrder) Execute(
 	if c.Supply%c.OutTick != 0 {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSupplyMisaligned}, nil
 	}
+	if Expired(c.Expiry, t) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOrderExpired}, nil
+	}
+	if ok, err := hasRole(ctx, r, db, actor, marketRoles); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	} else if !ok {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if failure := checkNotFrozen(ctx, db, c.In, c.Out); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
 	if err := storage.SubBalance(ctx, db, actor, c.Out, c.Supply); err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
-	if err := storage.SetEnergyOrder(ctx, db, txID, c.In, c.InTick, c.Out, c.OutTick, c.Supply, actor); err != nil {
+	if err := storage.SetEnergyOrder(
+		ctx, db, txID, c.Side, c.In, c.InTick,
+		c.Out, c.OutTick, c.Supply, actor, c.Expiry,
+		c.AllOrNone, c.MinFill,
+	); err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
 	return &chain.Result{Success: true, Units: unitsUsed}, nil
 }
 
 func (*CreateEnergyOrder) MaxUnits(chain.Rules) uint64 {
-	return consts.IDLen*2 + consts.Uint64Len*3
+	return 1 + consts.IDLen*2 + consts.Uint64Len*5 + 1
 }
 
 func (c *CreateEnergyOrder) Marshal(p *codec.Packer) {
+	p.PackByte(c.Side)
 	p.PackID(c.In)
 	p.PackUint64(c.InTick)
 	p.PackID(c.Out)
 	p.PackUint64(c.OutTick)
 	p.PackUint64(c.Supply)
+	p.PackInt64(c.Expiry)
+	p.PackBool(c.AllOrNone)
+	p.PackUint64(c.MinFill)
 }
 
 func UnmarshalCreateEnergyOrder(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
 	var create CreateEnergyOrder
+	create.Side = p.UnpackByte()
 	p.UnpackID(false, &create.In)
 	create.InTick = p.UnpackUint64(true)
 	p.UnpackID(false, &create.Out)
 	create.OutTick = p.UnpackUint64(true)
 	create.Supply = p.UnpackUint64(true)
-	return &create, p.Err()
+	create.Expiry = p.UnpackInt64(false) // 0 means no expiry
+	create.AllOrNone = p.UnpackBool()
+	create.MinFill = p.UnpackUint64(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if create.Side > SideBid || create.Expiry < 0 {
+		return nil, chain.ErrInvalidObject
+	}
+	return &create, nil
 }
 
 func (*CreateEnergyOrder) ValidRange(chain.Rules) (int64, int64) {
 	return -1, -1
 }
 
+// Expired returns true if an order with [expiry] can no longer be filled at
+// timestamp [t].
+func Expired(expiry int64, t int64) bool {
+	return expiry != 0 && t >= expiry
+}
+
 func PairID(in ids.ID, out ids.ID) string {
 	return fmt.Sprintf("%s-%s", in.String(), out.String())
 }
+
+// SymbolLookup returns the asset [symbol] is registered to, if any.
+type SymbolLookup func(symbol string) (ids.ID, bool, error)
+
+// ParseAsset returns the asset named by [s], which is either an asset ID or a
+// registered symbol. The native asset is always named by its symbol.
+func ParseAsset(s string, lookup SymbolLookup) (ids.ID, error) {
+	if s == nconsts.Symbol {
+		return ids.Empty, nil
+	}
+	if asset, err := ids.FromString(s); err == nil {
+		return asset, nil
+	}
+	if err := VerifySymbol(s); err != nil {
+		return ids.Empty, err
+	}
+	if lookup == nil {
+		return ids.Empty, fmt.Errorf("%w: %s", ErrSymbolMissing, s)
+	}
+	asset, ok, err := lookup(s)
+	if err != nil {
+		return ids.Empty, err
+	}
+	if !ok {
+		return ids.Empty, fmt.Errorf("%w: %s", ErrSymbolMissing, s)
+	}
+	return asset, nil
+}
+
+// ParsePair returns the [PairID] of [pair], which is denoted as
+// <asset 1>-<asset 2> where each asset is either an ID or a registered
+// symbol.
+func ParsePair(pair string, lookup SymbolLookup) (string, error) {
+	in, out, ok := strings.Cut(pair, "-")
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPair, pair)
+	}
+	inID, err := ParseAsset(in, lookup)
+	if err != nil {
+		return "", err
+	}
+	outID, err := ParseAsset(out, lookup)
+	if err != nil {
+		return "", err
+	}
+	return PairID(inID, outID), nil
+}
+
+// BookID returns the pair an order on [side] is listed under. Asks and bids
+// for the same market share a book, so bids are listed with their assets
+// reversed.
+func BookID(side uint8, in ids.ID, out ids.ID) string {
+	if side == SideBid {
+		return PairID(out, in)
+	}
+	return PairID(in, out)
+}
@@ -2,4 +2,17 @@ package actions
 
 import "errors"
 
-var ErrNoSwapToFill = errors.New("no swap to fill")
+var (
+	ErrNoSwapToFill        = errors.New("no swap to fill")
+	ErrMissingWarpMessage  = errors.New("missing warp message")
+	ErrTooManyCertificates = errors.New("too many certificates")
+	ErrInvalidRole         = errors.New("invalid role")
+
+	ErrInvalidMetadata            = errors.New("invalid metadata")
+	ErrUnsupportedMetadataVersion = errors.New("unsupported metadata version")
+	ErrInvalidUnit                = errors.New("invalid unit")
+
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	ErrSymbolMissing = errors.New("symbol is not registered")
+	ErrInvalidPair   = errors.New("invalid pair")
+)
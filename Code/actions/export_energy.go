@@ -0,0 +1,210 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*ExportEnergy)(nil)
+
+type ExportEnergy struct {
+	// To is the recipient of [Value] on [Destination].
+	To crypto.PublicKey `json:"to"`
+
+	// Asset is the asset to export. When [Return] is set, this is the
+	// warp asset that was previously imported to this subnet.
+	Asset ids.ID `json:"asset"`
+
+	// number of kilowatt hours to export
+	Value uint64 `json:"value"`
+
+	// Return is set when sending an imported asset back to the subnet it
+	// was initialized on. The asset is burned instead of locked.
+	Return bool `json:"return"`
+
+	// Destination is the chain that can import the warp message.
+	Destination ids.ID `json:"destination"`
+}
+
+func (e *ExportEnergy) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	actor := auth.GetActor(rauth)
+	if e.Return {
+		return [][]byte{
+			storage.PrefixAssetKey(e.Asset),
+			storage.PrefixBalanceKey(actor, e.Asset),
+		}
+	}
+	return [][]byte{
+		storage.PrefixAssetKey(e.Asset),
+		storage.CreditPrefixKey(e.Asset, e.Destination),
+		storage.PrefixBalanceKey(actor, e.Asset),
+	}
+}
+
+// executeReturn burns an imported asset so it can be released from credit on
+// the subnet it came from.
+func (e *ExportEnergy) executeReturn(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	actor crypto.PublicKey,
+	txID ids.ID,
+) (*chain.Result, error) {
+	unitsUsed := e.MaxUnits(r)
+	exists, metadata, supply, _, isWarp, _, err := storage.GetAsset(ctx, db, e.Asset)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetMissing}, nil
+	}
+	if !isWarp {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputNotWarpAsset}, nil
+	}
+	allowedDestination, err := ids.ToID(metadata[consts.IDLen:])
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if allowedDestination != e.Destination {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongDestination}, nil
+	}
+	if failure := checkNotFrozen(ctx, db, e.Asset); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if err := storage.SubBalance(ctx, db, actor, e.Asset, e.Value); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	newSupply, err := smath.Sub(supply, e.Value)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if newSupply > 0 {
+		if err := storage.SetAsset(ctx, db, e.Asset, metadata, newSupply, crypto.EmptyPublicKey, true, false); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	} else {
+		if err := storage.DeleteAsset(ctx, db, e.Asset); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+	originalAsset, err := ids.ToID(metadata[:consts.IDLen])
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return e.emit(unitsUsed, originalAsset, txID)
+}
+
+// executeCredit locks a local asset and credits it to [Destination] so it
+// can be released again when the kWh are returned.
+func (e *ExportEnergy) executeCredit(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	actor crypto.PublicKey,
+	txID ids.ID,
+) (*chain.Result, error) {
+	unitsUsed := e.MaxUnits(r)
+	exists, _, _, _, isWarp, _, err := storage.GetAsset(ctx, db, e.Asset)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetMissing}, nil
+	}
+	if isWarp {
+		// Cannot export an asset if it was warped in and not returning
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWarpAsset}, nil
+	}
+	if failure := checkNotFrozen(ctx, db, e.Asset); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if err := storage.SubBalance(ctx, db, actor, e.Asset, e.Value); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.AddCredit(ctx, db, e.Asset, e.Destination, e.Value); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return e.emit(unitsUsed, e.Asset, txID)
+}
+
+func (e *ExportEnergy) emit(unitsUsed uint64, asset ids.ID, txID ids.ID) (*chain.Result, error) {
+	wt := &WarpTransfer{
+		To:     e.To,
+		Asset:  asset,
+		Value:  e.Value,
+		Return: e.Return,
+		TxID:   txID,
+	}
+	payload, err := wt.Marshal()
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	wm := &warp.UnsignedMessage{
+		DestinationChainID: e.Destination,
+		// SourceChainID is populated by hypersdk
+		Payload: payload,
+	}
+	return &chain.Result{Success: true, Units: unitsUsed, WarpMessage: wm}, nil
+}
+
+func (e *ExportEnergy) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	txID ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := e.MaxUnits(r)
+	if e.Value == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+	if e.Destination == ids.Empty {
+		// This would result in multiplying the export by whoever imports the
+		// transaction.
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAnycast}, nil
+	}
+	if e.Return {
+		return e.executeReturn(ctx, r, db, actor, txID)
+	}
+	return e.executeCredit(ctx, r, db, actor, txID)
+}
+
+func (*ExportEnergy) MaxUnits(chain.Rules) uint64 {
+	return crypto.PublicKeyLen + consts.IDLen + consts.Uint64Len + 1 + consts.IDLen
+}
+
+func (e *ExportEnergy) Marshal(p *codec.Packer) {
+	p.PackPublicKey(e.To)
+	p.PackID(e.Asset)
+	p.PackUint64(e.Value)
+	p.PackBool(e.Return)
+	p.PackID(e.Destination)
+}
+
+func UnmarshalExportEnergy(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var export ExportEnergy
+	p.UnpackPublicKey(false, &export.To) // can transfer to blackhole
+	p.UnpackID(false, &export.Asset)     // may export native
+	export.Value = p.UnpackUint64(true)
+	export.Return = p.UnpackBool()
+	p.UnpackID(true, &export.Destination)
+	return &export, p.Err()
+}
+
+func (*ExportEnergy) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,255 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*FillBestEnergyOrders)(nil)
+
+// FillBestEnergyOrders buys up to [Quantity] of [Out] by filling [Orders] one
+// after another. Either all fills happen or none do.
+type FillBestEnergyOrders struct {
+	// [In] is the asset paid to the owners of the orders.
+	In ids.ID `json:"in"`
+
+	// [Out] is the asset received from the orders.
+	Out ids.ID `json:"out"`
+
+	// [Quantity] is the amount of [Out] to buy, before the taker fee is
+	// deducted.
+	Quantity uint64 `json:"quantity"`
+
+	// [MaxIn] is the most of [In] that can be spent across all fills. If
+	// filling the orders would cost more, the action fails.
+	MaxIn uint64 `json:"maxIn"`
+
+	// [Orders] are the candidate orders, best first. Orders that were filled,
+	// closed or expired since the tx was issued are skipped, as are orders
+	// whose flags don't allow the fill.
+	Orders []*OrderMatch `json:"orders"`
+
+	// [Treasury] is the account that collects the trading fees set in genesis.
+	// We need to provide this to populate [StateKeys].
+	Treasury crypto.PublicKey `json:"treasury"`
+}
+
+func (f *FillBestEnergyOrders) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	actor := auth.GetActor(rauth)
+	keys := make([][]byte, 0, 6+len(f.Orders)*2)
+	keys = append(
+		keys,
+		storage.PrefixBalanceKey(actor, f.In),
+		storage.PrefixBalanceKey(actor, f.Out),
+		storage.PrefixBalanceKey(f.Treasury, f.In),
+		storage.PrefixBalanceKey(f.Treasury, f.Out),
+	)
+	keys = append(keys, frozenKeys(f.In, f.Out)...)
+	for _, m := range f.Orders {
+		keys = append(
+			keys,
+			storage.PrefixEnergyOrderKey(m.Order),
+			storage.PrefixBalanceKey(m.Owner, f.In),
+		)
+	}
+	return keys
+}
+
+func (f *FillBestEnergyOrders) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := f.MaxUnits(r) // max units == units
+	if f.Quantity == 0 || f.MaxIn == 0 {
+		// This should be guarded via [Unmarshal] but we check anyways.
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+	if failure := checkNotFrozen(ctx, db, f.In, f.Out); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	makerFeeBips, takerFeeBips, treasury := tradingFees(r)
+	if treasury != f.Treasury {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongTreasury}, nil
+	}
+	var (
+		bought uint64
+		result = &EnergyOrdersResult{Fills: make([]*OrderFill, 0, len(f.Orders))}
+	)
+	for _, m := range f.Orders {
+		needed := f.Quantity - bought
+		if needed == 0 {
+			break
+		}
+		exists, side, in, inTick, out, outTick, remaining, owner, expiry, allOrNone, minFill, err := storage.GetEnergyOrder(ctx, db, m.Order)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if !exists || Expired(expiry, t) {
+			continue
+		}
+		if owner != m.Owner {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOwner}, nil
+		}
+		if in != f.In {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongIn}, nil
+		}
+		if out != f.Out {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOut}, nil
+		}
+
+		// Only buy whole ticks of [Out] that are still needed
+		if remaining < needed {
+			needed = remaining
+		}
+		value, err := smath.Mul64(needed/outTick, inTick)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if value == 0 {
+			continue
+		}
+		inputAmount, outputAmount, orderRemaining, failure := trade(inTick, outTick, remaining, value)
+		if failure != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+		}
+		if checkMakerFlags(allOrNone, minFill, outputAmount, remaining) != nil {
+			// The owner doesn't allow a fill of this size
+			continue
+		}
+		spent, err := smath.Add64(result.In, inputAmount)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if spent > f.MaxIn {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMaxInputExceeded}, nil
+		}
+		if err := storage.SubBalance(ctx, db, actor, f.In, inputAmount); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		makerFee := feeAmount(inputAmount, makerFeeBips)
+		takerFee := feeAmount(outputAmount, takerFeeBips)
+		if err := storage.AddBalance(ctx, db, owner, f.In, inputAmount-makerFee); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if err := storage.AddBalance(ctx, db, actor, f.Out, outputAmount-takerFee); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if makerFee > 0 {
+			if err := storage.AddBalance(ctx, db, f.Treasury, f.In, makerFee); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		}
+		if takerFee > 0 {
+			if err := storage.AddBalance(ctx, db, f.Treasury, f.Out, takerFee); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		}
+		if orderRemaining == 0 {
+			if err := storage.DeleteOrder(ctx, db, m.Order); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		} else {
+			if err := storage.SetEnergyOrder(
+				ctx, db, m.Order, side, in, inTick,
+				out, outTick, orderRemaining, owner, expiry,
+				allOrNone, minFill,
+			); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		}
+		bought += outputAmount
+		result.In = spent
+		result.Out += outputAmount - takerFee
+		result.Fills = append(result.Fills, &OrderFill{
+			m.Order, inputAmount, outputAmount - takerFee,
+			orderRemaining, makerFee, takerFee,
+		})
+	}
+	if len(result.Fills) == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputNothingFilled}, nil
+	}
+	output, err := result.Marshal()
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed, Output: output}, nil
+}
+
+func (f *FillBestEnergyOrders) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen*2 + consts.Uint64Len*2 + crypto.PublicKeyLen +
+		uint64(len(f.Orders))*(consts.IDLen+crypto.PublicKeyLen+tradeSucceededPrice)
+}
+
+func (f *FillBestEnergyOrders) Marshal(p *codec.Packer) {
+	p.PackID(f.In)
+	p.PackID(f.Out)
+	p.PackUint64(f.Quantity)
+	p.PackUint64(f.MaxIn)
+	packOrderMatches(p, f.Orders)
+	p.PackPublicKey(f.Treasury)
+}
+
+func UnmarshalFillBestEnergyOrders(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var fill FillBestEnergyOrders
+	p.UnpackID(false, &fill.In)
+	p.UnpackID(false, &fill.Out)
+	fill.Quantity = p.UnpackUint64(true)
+	fill.MaxIn = p.UnpackUint64(true)
+	orders, err := unpackOrderMatches(p, true)
+	if err != nil {
+		return nil, err
+	}
+	fill.Orders = orders
+	p.UnpackPublicKey(false, &fill.Treasury) // empty if there are no fees
+	return &fill, p.Err()
+}
+
+func (*FillBestEnergyOrders) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+// EnergyOrdersResult provides information about a successful
+// [FillBestEnergyOrders]. [In] and [Out] are the totals across all [Fills],
+// with [Out] after the taker fee.
+type EnergyOrdersResult struct {
+	Fills []*OrderFill `json:"fills"`
+	In    uint64       `json:"in"`
+	Out   uint64       `json:"out"`
+}
+
+func UnmarshalOrdersResult(b []byte) (*EnergyOrdersResult, error) {
+	p := codec.NewReader(b, consts.IntLen+MaxOrderMatches*orderFillLen+consts.Uint64Len*2)
+	var result EnergyOrdersResult
+	fills, err := unpackOrderFills(p, true)
+	if err != nil {
+		return nil, err
+	}
+	result.Fills = fills
+	result.In = p.UnpackUint64(true)
+	result.Out = p.UnpackUint64(false) // can be 0 if the taker fee is 100%
+	return &result, p.Err()
+}
+
+func (o *EnergyOrdersResult) Marshal() ([]byte, error) {
+	p := codec.NewWriter(consts.IntLen + len(o.Fills)*orderFillLen + consts.Uint64Len*2)
+	packOrderFills(p, o.Fills)
+	p.PackUint64(o.In)
+	p.PackUint64(o.Out)
+	return p.Bytes(), p.Err()
+}
@@ -2,6 +2,7 @@ package actions
 
 import (
 	"context"
+	"math/bits"
 
 	"github.com/ava-labs/avalanchego/ids"
 	smath "github.com/ava-labs/avalanchego/utils/math"
@@ -11,15 +12,17 @@ import (
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/crypto"
-	"github.com/ava-labs/hypersdk/examples/tokenvm/auth"
-	"github.com/ava-labs/hypersdk/examples/tokenvm/storage"
 	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/genesis"
+	"github.com/bbehrman10/energyavavm/storage"
 )
 
 var _ chain.Action = (*FillEnergyOrder)(nil)
 
 const (
-	basePrice           = 3*consts.IDLen + consts.Uint64Len + crypto.PublicKeyLen
+	basePrice           = 3*consts.IDLen + 2*consts.Uint64Len + 2*crypto.PublicKeyLen + 1
 	tradeSucceededPrice = 1_000
 )
 
@@ -41,29 +44,45 @@ type FillEnergyOrder struct {
 
 	// [Value] is the max amount of [In] that will be swapped for [Out].
 	Value uint64 `json:"value"`
+
+	// [FillOrKill] fails the fill if not all of [Value] can be swapped.
+	FillOrKill bool `json:"fillOrKill"`
+
+	// [MinReceived] fails the fill if less [Out] would be received, after the
+	// taker fee is deducted.
+	MinReceived uint64 `json:"minReceived"`
+
+	// [Treasury] is the account that collects the trading fees set in genesis.
+	// We need to provide this to populate [StateKeys].
+	Treasury crypto.PublicKey `json:"treasury"`
 }
 
 func (f *FillEnergyOrder) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
 	actor := auth.GetActor(rauth)
-	return [][]byte{
-		storage.PrefixOrderKey(f.Order),
-		storage.PrefixBalanceKey(f.Owner, f.In),
-		storage.PrefixBalanceKey(actor, f.In),
-		storage.PrefixBalanceKey(actor, f.Out),
-	}
+	return append(
+		[][]byte{
+			storage.PrefixEnergyOrderKey(f.Order),
+			storage.PrefixBalanceKey(f.Owner, f.In),
+			storage.PrefixBalanceKey(actor, f.In),
+			storage.PrefixBalanceKey(actor, f.Out),
+			storage.PrefixBalanceKey(f.Treasury, f.In),
+			storage.PrefixBalanceKey(f.Treasury, f.Out),
+		},
+		frozenKeys(f.In, f.Out)...,
+	)
 }
 
 func (f *FillEnergyOrder) Execute(
 	ctx context.Context,
-	_ chain.Rules,
+	r chain.Rules,
 	db chain.Database,
-	_ int64,
+	t int64,
 	rauth chain.Auth,
 	_ ids.ID,
 	_ bool,
 ) (*chain.Result, error) {
 	actor := auth.GetActor(rauth)
-	exists, in, inTick, out, outTick, remaining, owner, err := storage.GetOrder(ctx, db, f.Order)
+	exists, side, in, inTick, out, outTick, remaining, owner, expiry, allOrNone, minFill, err := storage.GetEnergyOrder(ctx, db, f.Order)
 	if err != nil {
 		return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
 	}
@@ -73,6 +92,9 @@ func (f *FillEnergyOrder) Execute(
 	if owner != f.Owner {
 		return &chain.Result{Success: false, Units: basePrice, Output: OutputWrongOwner}, nil
 	}
+	if Expired(expiry, t) {
+		return &chain.Result{Success: false, Units: basePrice, Output: OutputOrderExpired}, nil
+	}
 	if in != f.In {
 		return &chain.Result{Success: false, Units: basePrice, Output: OutputWrongIn}, nil
 	}
@@ -83,68 +105,67 @@ func (f *FillEnergyOrder) Execute(
 		// This should be guarded via [Unmarshal] but we check anyways.
 		return &chain.Result{Success: false, Units: basePrice, Output: OutputValueZero}, nil
 	}
-	if f.Value%inTick != 0 {
-		return &chain.Result{Success: false, Units: basePrice, Output: OutputValueMisaligned}, nil
+	if failure := checkNotFrozen(ctx, db, f.In, f.Out); failure != nil {
+		return &chain.Result{Success: false, Units: basePrice, Output: failure}, nil
 	}
-	// Determine amount of [Out] counterparty will receive if the trade is
-	// successful.
-	outputAmount, err := smath.Mul64(outTick, f.Value/inTick)
-	if err != nil {
-		return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
+	inputAmount, outputAmount, orderRemaining, failure := trade(inTick, outTick, remaining, f.Value)
+	if failure != nil {
+		return &chain.Result{Success: false, Units: basePrice, Output: failure}, nil
 	}
-	if outputAmount == 0 {
-		// This should never happen because [f.Value] > 0
-		return &chain.Result{
-			Success: false,
-			Units:   basePrice,
-			Output:  OutputInsufficientOutput,
-		}, nil
+	if failure := checkMakerFlags(allOrNone, minFill, outputAmount, remaining); failure != nil {
+		return &chain.Result{Success: false, Units: basePrice, Output: failure}, nil
 	}
-	var (
-		inputAmount    = f.Value
-		shouldDelete   = false
-		orderRemaining uint64
-	)
-	switch {
-	case outputAmount > remaining:
-		// Calculate correct input given remaining supply
-		//
-		// This may happen if 2 people try to trade the same order at once.
-		blocksOver := (outputAmount - remaining) / outTick
-		inputAmount -= blocksOver * inTick
-
-		// If the [outputAmount] is greater than remaining, take what is left.
-		outputAmount = remaining
-		shouldDelete = true
-	case outputAmount == remaining:
-		// If the [outputAmount] is equal to remaining, take all of it.
-		shouldDelete = true
-	default:
-		orderRemaining = remaining - outputAmount
+	if f.FillOrKill && inputAmount != f.Value {
+		return &chain.Result{Success: false, Units: basePrice, Output: OutputFillOrKill}, nil
 	}
-	if inputAmount == 0 {
-		// Don't allow free trades (can happen due to refund rounding)
-		return &chain.Result{Success: false, Units: basePrice, Output: OutputInsufficientInput}, nil
+	makerFeeBips, takerFeeBips, treasury := tradingFees(r)
+	if treasury != f.Treasury {
+		return &chain.Result{Success: false, Units: basePrice, Output: OutputWrongTreasury}, nil
+	}
+	makerFee := feeAmount(inputAmount, makerFeeBips)
+	takerFee := feeAmount(outputAmount, takerFeeBips)
+	if outputAmount-takerFee < f.MinReceived {
+		return &chain.Result{Success: false, Units: basePrice, Output: OutputBelowMinReceived}, nil
 	}
 	if err := storage.SubBalance(ctx, db, actor, f.In, inputAmount); err != nil {
 		return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
 	}
-	if err := storage.AddBalance(ctx, db, f.Owner, f.In, inputAmount); err != nil {
+	if err := storage.AddBalance(ctx, db, f.Owner, f.In, inputAmount-makerFee); err != nil {
 		return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
 	}
-	if err := storage.AddBalance(ctx, db, actor, f.Out, outputAmount); err != nil {
+	if err := storage.AddBalance(ctx, db, actor, f.Out, outputAmount-takerFee); err != nil {
 		return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
 	}
-	if shouldDelete {
+	if makerFee > 0 {
+		if err := storage.AddBalance(ctx, db, f.Treasury, f.In, makerFee); err != nil {
+			return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+	if takerFee > 0 {
+		if err := storage.AddBalance(ctx, db, f.Treasury, f.Out, takerFee); err != nil {
+			return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+	if orderRemaining == 0 {
 		if err := storage.DeleteOrder(ctx, db, f.Order); err != nil {
 			return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
 		}
 	} else {
-		if err := storage.SetOrder(ctx, db, f.Order, in, inTick, out, outTick, orderRemaining, owner); err != nil {
+		if err := storage.SetEnergyOrder(
+			ctx, db, f.Order, side, in, inTick,
+			out, outTick, orderRemaining, owner, expiry,
+			allOrNone, minFill,
+		); err != nil {
 			return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
 		}
 	}
-	or := &EnergyOrderResult{In: inputAmount, Out: outputAmount, Remaining: orderRemaining}
+	or := &EnergyOrderResult{
+		In:        inputAmount,
+		Out:       outputAmount - takerFee,
+		Remaining: orderRemaining,
+		MakerFee:  makerFee,
+		TakerFee:  takerFee,
+	}
 	output, err := or.Marshal()
 	if err != nil {
 		return &chain.Result{Success: false, Units: basePrice, Output: utils.ErrBytes(err)}, nil
@@ -162,6 +183,9 @@ func (f *FillEnergyOrder) Marshal(p *codec.Packer) {
 	p.PackID(f.In)
 	p.PackID(f.Out)
 	p.PackUint64(f.Value)
+	p.PackBool(f.FillOrKill)
+	p.PackUint64(f.MinReceived)
+	p.PackPublicKey(f.Treasury)
 }
 
 func UnmarshalFillOrder(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
@@ -171,6 +195,9 @@ func UnmarshalFillOrder(p *codec.Packer, _ *warp.Message) (chain.Action, error)
 	p.UnpackID(false, &fill.In)
 	p.UnpackID(false, &fill.Out)
 	fill.Value = p.UnpackUint64(true)
+	fill.FillOrKill = p.UnpackBool()
+	fill.MinReceived = p.UnpackUint64(false)
+	p.UnpackPublicKey(false, &fill.Treasury) // empty if there are no fees
 	return &fill, p.Err()
 }
 
@@ -178,26 +205,130 @@ func (*FillEnergyOrder) ValidRange(chain.Rules) (int64, int64) {
 	return -1, -1
 }
 
-// Provides information about a successful trade.
+// trade determines how much of [value] is swapped against an order with
+// [inTick], [outTick] and [remaining] supply. It returns the amount of [In]
+// taken, the amount of [Out] given and the supply left on the order. If the
+// trade is not possible, [failure] is set to the output of the failed action.
+func trade(inTick uint64, outTick uint64, remaining uint64, value uint64) (
+	inputAmount uint64,
+	outputAmount uint64,
+	orderRemaining uint64,
+	failure []byte,
+) {
+	if value%inTick != 0 {
+		return 0, 0, 0, OutputValueMisaligned
+	}
+	// Determine amount of [Out] counterparty will receive if the trade is
+	// successful.
+	outputAmount, err := smath.Mul64(outTick, value/inTick)
+	if err != nil {
+		return 0, 0, 0, utils.ErrBytes(err)
+	}
+	if outputAmount == 0 {
+		// This should never happen because [value] > 0
+		return 0, 0, 0, OutputInsufficientOutput
+	}
+	inputAmount = value
+	switch {
+	case outputAmount > remaining:
+		// Calculate correct input given remaining supply
+		//
+		// This may happen if 2 people try to trade the same order at once.
+		blocksOver := (outputAmount - remaining) / outTick
+		inputAmount -= blocksOver * inTick
+
+		// If the [outputAmount] is greater than remaining, take what is left.
+		outputAmount = remaining
+	case outputAmount == remaining:
+		// If the [outputAmount] is equal to remaining, take all of it.
+	default:
+		orderRemaining = remaining - outputAmount
+	}
+	if inputAmount == 0 {
+		// Don't allow free trades (can happen due to refund rounding)
+		return 0, 0, 0, OutputInsufficientInput
+	}
+	return inputAmount, outputAmount, orderRemaining, nil
+}
+
+// checkMakerFlags returns the output of the failed action if a fill that
+// gives [outputAmount] of an order with [remaining] supply is not allowed by
+// the [allOrNone] and [minFill] flags of the order.
+func checkMakerFlags(allOrNone bool, minFill uint64, outputAmount uint64, remaining uint64) []byte {
+	if outputAmount == remaining {
+		// Taking all that is left is always allowed
+		return nil
+	}
+	if allOrNone {
+		return OutputOrderAllOrNone
+	}
+	if outputAmount < minFill {
+		return OutputBelowMinFill
+	}
+	return nil
+}
+
+// tradingFees returns the maker and taker fees, in basis points, and the
+// treasury that collects them. Missing parameters mean no fees are charged.
+func tradingFees(r chain.Rules) (uint64, uint64, crypto.PublicKey) {
+	var (
+		makerFeeBips uint64
+		takerFeeBips uint64
+		treasury     = crypto.EmptyPublicKey
+	)
+	if v, ok := r.FetchCustom(genesis.MakerFeeBipsField); ok {
+		makerFeeBips, _ = v.(uint64)
+	}
+	if v, ok := r.FetchCustom(genesis.TakerFeeBipsField); ok {
+		takerFeeBips, _ = v.(uint64)
+	}
+	if v, ok := r.FetchCustom(genesis.TreasuryField); ok {
+		if pk, ok := v.(crypto.PublicKey); ok {
+			treasury = pk
+		}
+	}
+	return makerFeeBips, takerFeeBips, treasury
+}
+
+// feeAmount returns [bips] basis points of [amount], rounded down.
+func feeAmount(amount uint64, bips uint64) uint64 {
+	if bips > genesis.MaxFeeBips {
+		// This is checked in genesis but we check anyways.
+		bips = genesis.MaxFeeBips
+	}
+	hi, lo := bits.Mul64(amount, bips)
+	fee, _ := bits.Div64(hi, lo, genesis.MaxFeeBips)
+	return fee
+}
+
+// Provides information about a successful trade. [Out] is what the filler
+// received after the [TakerFee] and the owner received [In] less the
+// [MakerFee].
 type EnergyOrderResult struct {
 	In        uint64 `json:"in"`
 	Out       uint64 `json:"out"`
 	Remaining uint64 `json:"remaining"`
+	MakerFee  uint64 `json:"makerFee"`
+	TakerFee  uint64 `json:"takerFee"`
 }
 
 func UnmarshalOrderResult(b []byte) (*EnergyOrderResult, error) {
-	p := codec.NewReader(b, consts.Uint64Len*3)
+	p := codec.NewReader(b, consts.Uint64Len*5)
 	var result EnergyOrderResult
 	result.In = p.UnpackUint64(true)
-	result.Out = p.UnpackUint64(true)
+	result.Out = p.UnpackUint64(false)       // can be 0 if the taker fee is 100%
 	result.Remaining = p.UnpackUint64(false) // if 0, delete
+	result.MakerFee = p.UnpackUint64(false)
+	result.TakerFee = p.UnpackUint64(false)
 	return &result, p.Err()
 }
 
 func (o *EnergyOrderResult) Marshal() ([]byte, error) {
-	p := codec.NewWriter(consts.Uint64Len * 3)
+	p := codec.NewWriter(consts.Uint64Len * 5)
 	p.PackUint64(o.In)
 	p.PackUint64(o.Out)
 	p.PackUint64(o.Remaining)
+	p.PackUint64(o.MakerFee)
+	p.PackUint64(o.TakerFee)
 	return p.Bytes(), p.Err()
 }
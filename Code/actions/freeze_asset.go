@@ -0,0 +1,156 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var (
+	_ chain.Action = (*FreezeAsset)(nil)
+	_ chain.Action = (*UnfreezeAsset)(nil)
+)
+
+// FreezeAsset stops [Asset] from being produced, transferred or filled until
+// it is unfrozen. Only the owner of [Asset] can freeze it.
+type FreezeAsset struct {
+	// [Asset] is the asset to freeze.
+	Asset ids.ID `json:"asset"`
+}
+
+func (f *FreezeAsset) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixAssetKey(f.Asset)}
+}
+
+func (f *FreezeAsset) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	return setFrozen(ctx, db, f.Asset, auth.GetActor(rauth), true, f.MaxUnits(r))
+}
+
+func (*FreezeAsset) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen
+}
+
+func (f *FreezeAsset) Marshal(p *codec.Packer) {
+	p.PackID(f.Asset)
+}
+
+func UnmarshalFreezeAsset(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var freeze FreezeAsset
+	p.UnpackID(true, &freeze.Asset) // cannot freeze native asset
+	return &freeze, p.Err()
+}
+
+func (*FreezeAsset) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+// UnfreezeAsset lifts a [FreezeAsset]. Only the owner of [Asset] can
+// unfreeze it.
+type UnfreezeAsset struct {
+	// [Asset] is the asset to unfreeze.
+	Asset ids.ID `json:"asset"`
+}
+
+func (u *UnfreezeAsset) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixAssetKey(u.Asset)}
+}
+
+func (u *UnfreezeAsset) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	return setFrozen(ctx, db, u.Asset, auth.GetActor(rauth), false, u.MaxUnits(r))
+}
+
+func (*UnfreezeAsset) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen
+}
+
+func (u *UnfreezeAsset) Marshal(p *codec.Packer) {
+	p.PackID(u.Asset)
+}
+
+func UnmarshalUnfreezeAsset(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var unfreeze UnfreezeAsset
+	p.UnpackID(true, &unfreeze.Asset) // cannot unfreeze native asset
+	return &unfreeze, p.Err()
+}
+
+func (*UnfreezeAsset) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+func setFrozen(
+	ctx context.Context,
+	db chain.Database,
+	asset ids.ID,
+	actor crypto.PublicKey,
+	frozen bool,
+	unitsUsed uint64,
+) (*chain.Result, error) {
+	metadata, supply, wasFrozen, failure := getOwnedAsset(ctx, db, asset, actor)
+	if failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if wasFrozen == frozen {
+		if frozen {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetFrozen}, nil
+		}
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetNotFrozen}, nil
+	}
+	if err := storage.SetAsset(ctx, db, asset, metadata, supply, actor, false, frozen); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+// checkNotFrozen returns [OutputAssetFrozen] if any of [assets] is frozen. The
+// native asset can't be frozen and is not read.
+func checkNotFrozen(ctx context.Context, db chain.Database, assets ...ids.ID) []byte {
+	for _, asset := range assets {
+		if asset == ids.Empty {
+			continue
+		}
+		_, _, _, _, _, frozen, err := storage.GetAsset(ctx, db, asset)
+		if err != nil {
+			return utils.ErrBytes(err)
+		}
+		if frozen {
+			return OutputAssetFrozen
+		}
+	}
+	return nil
+}
+
+// frozenKeys returns the state keys [checkNotFrozen] reads for [assets].
+func frozenKeys(assets ...ids.ID) [][]byte {
+	keys := make([][]byte, 0, len(assets))
+	for _, asset := range assets {
+		if asset == ids.Empty {
+			continue
+		}
+		keys = append(keys, storage.PrefixAssetKey(asset))
+	}
+	return keys
+}
@@ -0,0 +1,122 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/genesis"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*GrantRole)(nil)
+
+// GrantRole gives [Role] to [To]. Only operators can grant roles.
+type GrantRole struct {
+	// [To] is the account that receives [Role].
+	To crypto.PublicKey `json:"to"`
+
+	// [Role] is a single one of the roles defined in storage.
+	Role uint8 `json:"role"`
+}
+
+func (g *GrantRole) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixRoleKey(auth.GetActor(rauth)),
+		storage.PrefixRoleKey(g.To),
+	}
+}
+
+func (g *GrantRole) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := g.MaxUnits(r)
+	if !ValidRole(g.Role) {
+		// This should be guarded via [Unmarshal] but we check anyways.
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInvalidRole}, nil
+	}
+	actorRoles, err := storage.GetRoles(ctx, db, actor)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if actorRoles&storage.RoleOperator == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	roles, err := storage.GetRoles(ctx, db, g.To)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if roles&g.Role != 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputRoleExists}, nil
+	}
+	if err := storage.SetRoles(ctx, db, g.To, roles|g.Role); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*GrantRole) MaxUnits(chain.Rules) uint64 {
+	return crypto.PublicKeyLen + 1
+}
+
+func (g *GrantRole) Marshal(p *codec.Packer) {
+	p.PackPublicKey(g.To)
+	p.PackByte(g.Role)
+}
+
+func UnmarshalGrantRole(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var grant GrantRole
+	p.UnpackPublicKey(true, &grant.To)
+	grant.Role = p.UnpackByte()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if !ValidRole(grant.Role) {
+		return nil, ErrInvalidRole
+	}
+	return &grant, nil
+}
+
+func (*GrantRole) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+// ValidRole returns whether [role] is exactly one of the roles defined in
+// storage.
+func ValidRole(role uint8) bool {
+	return role != 0 && role&storage.AllRoles == role && role&(role-1) == 0
+}
+
+// marketRoles are the roles that can list orders.
+const marketRoles = storage.RoleProducer | storage.RoleRetailer | storage.RoleConsumer
+
+// hasRole returns whether [actor] holds any of [roles]. If the chain is not
+// permissioned, every account is treated as holding every role.
+func hasRole(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	actor crypto.PublicKey,
+	roles uint8,
+) (bool, error) {
+	if v, ok := r.FetchCustom(genesis.PermissionedField); !ok || v != true {
+		return true, nil
+	}
+	actorRoles, err := storage.GetRoles(ctx, db, actor)
+	if err != nil {
+		return false, err
+	}
+	return actorRoles&roles != 0, nil
+}
@@ -0,0 +1,145 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*ImportEnergy)(nil)
+
+type ImportEnergy struct {
+	// warpTransfer is parsed from the inner *warp.Message
+	warpTransfer *WarpTransfer
+
+	// warpMessage is the full *warp.Message parsed from [chain.Transaction]
+	warpMessage *warp.Message
+}
+
+func (i *ImportEnergy) StateKeys(chain.Auth, ids.ID) [][]byte {
+	if i.warpTransfer.Return {
+		return [][]byte{
+			storage.CreditPrefixKey(i.warpTransfer.Asset, i.warpMessage.SourceChainID),
+			storage.PrefixBalanceKey(i.warpTransfer.To, i.warpTransfer.Asset),
+		}
+	}
+	assetID := ImportedAssetID(i.warpTransfer.Asset, i.warpMessage.SourceChainID)
+	return [][]byte{
+		storage.PrefixAssetKey(assetID),
+		storage.PrefixBalanceKey(i.warpTransfer.To, assetID),
+	}
+}
+
+// executeMint mints a warp asset that represents kWh locked on the source
+// subnet.
+func (i *ImportEnergy) executeMint(ctx context.Context, db chain.Database) []byte {
+	asset := ImportedAssetID(i.warpTransfer.Asset, i.warpMessage.SourceChainID)
+	exists, metadata, supply, _, isWarp, _, err := storage.GetAsset(ctx, db, asset)
+	if err != nil {
+		return utils.ErrBytes(err)
+	}
+	if exists && !isWarp {
+		// Should not be possible
+		return OutputConflictingAsset
+	}
+	if !exists {
+		metadata = ImportedAssetMetadata(i.warpTransfer.Asset, i.warpMessage.SourceChainID)
+	}
+	newSupply, err := smath.Add64(supply, i.warpTransfer.Value)
+	if err != nil {
+		return utils.ErrBytes(err)
+	}
+	if err := storage.SetAsset(ctx, db, asset, metadata, newSupply, crypto.EmptyPublicKey, true, false); err != nil {
+		return utils.ErrBytes(err)
+	}
+	if err := storage.AddBalance(ctx, db, i.warpTransfer.To, asset, i.warpTransfer.Value); err != nil {
+		return utils.ErrBytes(err)
+	}
+	return nil
+}
+
+// executeReturn releases kWh that were credited to the source subnet when
+// they were exported.
+func (i *ImportEnergy) executeReturn(ctx context.Context, db chain.Database) []byte {
+	if err := storage.SubCredit(
+		ctx, db, i.warpTransfer.Asset,
+		i.warpMessage.SourceChainID, i.warpTransfer.Value,
+	); err != nil {
+		return utils.ErrBytes(err)
+	}
+	if err := storage.AddBalance(
+		ctx, db, i.warpTransfer.To,
+		i.warpTransfer.Asset, i.warpTransfer.Value,
+	); err != nil {
+		return utils.ErrBytes(err)
+	}
+	return nil
+}
+
+func (i *ImportEnergy) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	_ chain.Auth,
+	_ ids.ID,
+	warpVerified bool,
+) (*chain.Result, error) {
+	unitsUsed := i.MaxUnits(r)
+	if !warpVerified {
+		return &chain.Result{
+			Success: false,
+			Units:   unitsUsed,
+			Output:  OutputWarpVerificationFailed,
+		}, nil
+	}
+	if i.warpTransfer.Value == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+	var output []byte
+	if i.warpTransfer.Return {
+		output = i.executeReturn(ctx, db)
+	} else {
+		output = i.executeMint(ctx, db)
+	}
+	if len(output) > 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: output}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (i *ImportEnergy) MaxUnits(chain.Rules) uint64 {
+	return uint64(len(i.warpMessage.Payload))
+}
+
+// Everything needed to execute an import is in the warp message, so there is
+// nothing action specific to encode.
+func (*ImportEnergy) Marshal(*codec.Packer) {}
+
+func UnmarshalImportEnergy(_ *codec.Packer, wm *warp.Message) (chain.Action, error) {
+	if wm == nil {
+		return nil, ErrMissingWarpMessage
+	}
+	var (
+		imp ImportEnergy
+		err error
+	)
+	imp.warpMessage = wm
+	imp.warpTransfer, err = UnmarshalWarpTransfer(imp.warpMessage.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (*ImportEnergy) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -15,11 +15,15 @@ import (
 var _ chain.Action = (*InitializeEnergyAsset)(nil)
 
 type InitializeEnergyAsset struct {
+	// Metadata is an encoded [AssetMetadata] describing the asset.
 	Metadata []byte `json:"metadata"`
 }
 
-func (*InitializeEnergyAsset) StateKeys(_ chain.Auth, txID ids.ID) [][]byte {
-	return [][]byte{storage.PrefixAssetKey(txID)}
+func (*InitializeEnergyAsset) StateKeys(rauth chain.Auth, txID ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixAssetKey(txID),
+		storage.PrefixRoleKey(auth.GetActor(rauth)),
+	}
 }
 
 func (c *InitializeEnergyAsset) Execute(
@@ -36,7 +40,16 @@ func (c *InitializeEnergyAsset) Execute(
 	if len(c.Metadata) > MaxMetadataSize {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMetadataTooLarge}, nil
 	}
-	if err := storage.SetAsset(ctx, db, txID, c.Metadata, 0, actor, false); err != nil {
+	metadata, failure := parseAssetMetadata(c.Metadata)
+	if failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if ok, err := hasRole(ctx, r, db, actor, storage.RoleProducer); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	} else if !ok {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if err := storage.SetAsset(ctx, db, txID, metadata, 0, actor, false, false); err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
 	return &chain.Result{Success: true, Units: unitsUsed}, nil
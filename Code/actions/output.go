@@ -18,6 +18,7 @@ var (
 	OutputInsufficientOutput     = []byte("insufficient output")
 	OutputValueMisaligned        = []byte("value is misaligned")
 	OutputMetadataTooLarge       = []byte("metadata is too large")
+	OutputMemoTooLarge           = []byte("memo is too large")
 	OutputSameInOut              = []byte("same asset used for in and out")
 	OutputConflictingAsset       = []byte("warp has same asset as another")
 	OutputAnycast                = []byte("anycast output")
@@ -26,4 +27,37 @@ var (
 	OutputWrongDestination       = []byte("wrong destination")
 	OutputMustFill               = []byte("must fill request")
 	OutputWarpVerificationFailed = []byte("warp verification failed")
+	OutputCapacityZero           = []byte("capacity is zero")
+	OutputMeterMissing           = []byte("meter is missing")
+	OutputInvalidInterval        = []byte("interval end is not after start")
+	OutputIntervalOverlap        = []byte("interval overlaps last reading")
+	OutputReadingInFuture        = []byte("interval ends in the future")
+	OutputReadingDecreased       = []byte("reading is below last reading")
+	OutputInvalidMeterSignature  = []byte("invalid meter signature")
+	OutputExceedsCapacity        = []byte("energy exceeds meter capacity")
+	OutputTooManyCertificates    = []byte("more certificates than MWh produced")
+	OutputCertificateMissing     = []byte("certificate is missing")
+	OutputCertificateRetired     = []byte("certificate is retired")
+	OutputOrderExpired           = []byte("order is expired")
+	OutputOrderNotExpired        = []byte("order is not expired")
+	OutputWrongSide              = []byte("wrong order side")
+	OutputNothingFilled          = []byte("no orders filled")
+	OutputMaxInputExceeded       = []byte("max input exceeded")
+	OutputOrderAllOrNone         = []byte("order must be filled in full")
+	OutputBelowMinFill           = []byte("fill is below order minimum")
+	OutputFillOrKill             = []byte("value cannot be filled in full")
+	OutputBelowMinReceived       = []byte("output is below minimum received")
+	OutputWrongTreasury          = []byte("wrong treasury")
+	OutputInvalidRole            = []byte("invalid role")
+	OutputRoleExists             = []byte("role already granted")
+	OutputRoleMissing            = []byte("role not granted")
+	OutputAssetFrozen            = []byte("asset is frozen")
+	OutputAssetNotFrozen         = []byte("asset is not frozen")
+	OutputInvalidMetadata        = []byte("invalid metadata")
+	OutputInvalidSymbol          = []byte("invalid symbol")
+	OutputSymbolTaken            = []byte("symbol is taken")
+	OutputAssetHasSymbol         = []byte("asset already has a symbol")
+	OutputSupplyBelowMinFill     = []byte("supply is below order minimum")
+	OutputSideMismatch           = []byte("side does not match assets")
+	OutputSelfRevoke             = []byte("cannot revoke own operator role")
 )
@@ -30,10 +30,11 @@ type ProduceEnergy struct {
 	Value uint64 `json:"value"`
 }
 
-func (m *ProduceEnergy) StateKeys(chain.Auth, ids.ID) [][]byte {
+func (m *ProduceEnergy) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
 	return [][]byte{
 		storage.PrefixAssetKey(m.Asset),
 		storage.PrefixBalanceKey(m.To, m.Asset),
+		storage.PrefixRoleKey(auth.GetActor(rauth)),
 	}
 }
 
@@ -54,7 +55,12 @@ func (m *ProduceEnergy) Execute(
 	if m.Value == 0 {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
 	}
-	exists, metadata, supply, owner, isWarp, err := storage.GetAsset(ctx, db, m.Asset)
+	if ok, err := hasRole(ctx, r, db, actor, storage.RoleProducer); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	} else if !ok {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	exists, metadata, supply, owner, isWarp, frozen, err := storage.GetAsset(ctx, db, m.Asset)
 	if err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
@@ -71,11 +77,14 @@ func (m *ProduceEnergy) Execute(
 			Output:  OutputWrongOwner,
 		}, nil
 	}
+	if frozen {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetFrozen}, nil
+	}
 	newSupply, err := smath.Add64(supply, m.Value)
 	if err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
-	if err := storage.SetAsset(ctx, db, m.Asset, metadata, newSupply, actor, isWarp); err != nil {
+	if err := storage.SetAsset(ctx, db, m.Asset, metadata, newSupply, actor, isWarp, false); err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
 	}
 	if err := storage.AddBalance(ctx, db, m.To, m.Asset, m.Value); err != nil {
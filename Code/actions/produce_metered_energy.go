@@ -0,0 +1,247 @@
+package actions
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+const (
+	secondsPerHour = 3600
+	whPerKWh       = 1_000
+	whPerMWh       = 1_000_000
+
+	// certificateUnits is charged per issued certificate as each one adds a
+	// record to state.
+	certificateUnits = consts.IDLen + consts.Uint64Len*2 + crypto.PublicKeyLen + 1 + MaxCertificateFieldSize*2
+)
+
+var _ chain.Action = (*ProduceMeteredEnergy)(nil)
+
+// ProduceMeteredEnergy mints kWh backed by a reading signed by a registered
+// meter.
+type ProduceMeteredEnergy struct {
+	// To is the recipient of the minted kWh.
+	To crypto.PublicKey `json:"to"`
+
+	// Asset is the [TxID] that created the asset.
+	Asset ids.ID `json:"asset"`
+
+	// Meter is the [TxID] that registered the meter.
+	Meter ids.ID `json:"meter"`
+
+	// Start and End are the unix timestamps (in seconds) bounding the interval
+	// the reading covers.
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+
+	// Reading is the cumulative Wh shown on the meter at [End].
+	Reading uint64 `json:"reading"`
+
+	// Signature is the meter's signature over [ReadingMessage].
+	Signature crypto.Signature `json:"signature"`
+
+	// Certificates is the number of certificates to issue to [To] for this
+	// reading, one per MWh. It cannot exceed the number of MWh the meter's
+	// counter crossed during the interval.
+	Certificates uint8 `json:"certificates"`
+}
+
+// CertificateID is the ID of the [index]th certificate issued by [txID].
+func CertificateID(txID ids.ID, index uint8) ids.ID {
+	b := make([]byte, consts.IDLen+1)
+	copy(b, txID[:])
+	b[consts.IDLen] = index
+	return utils.ToID(b)
+}
+
+// ReadingMessage is the message a meter signs to attest to a reading.
+func ReadingMessage(meter ids.ID, start int64, end int64, reading uint64) []byte {
+	msg := make([]byte, consts.IDLen+consts.Uint64Len*3)
+	copy(msg, meter[:])
+	binary.BigEndian.PutUint64(msg[consts.IDLen:], uint64(start))
+	binary.BigEndian.PutUint64(msg[consts.IDLen+consts.Uint64Len:], uint64(end))
+	binary.BigEndian.PutUint64(msg[consts.IDLen+consts.Uint64Len*2:], reading)
+	return msg
+}
+
+func (m *ProduceMeteredEnergy) StateKeys(rauth chain.Auth, txID ids.ID) [][]byte {
+	keys := [][]byte{
+		storage.PrefixAssetKey(m.Asset),
+		storage.PrefixMeterKey(m.Meter),
+		storage.PrefixBalanceKey(m.To, m.Asset),
+		storage.PrefixRoleKey(auth.GetActor(rauth)),
+	}
+	for i := uint8(0); i < m.Certificates; i++ {
+		keys = append(keys, storage.PrefixCertificateKey(CertificateID(txID, i)))
+	}
+	return keys
+}
+
+func (m *ProduceMeteredEnergy) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	rauth chain.Auth,
+	txID ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := m.MaxUnits(r)
+	if m.Asset == ids.Empty {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetIsNative}, nil
+	}
+	if ok, err := hasRole(ctx, r, db, actor, storage.RoleProducer); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	} else if !ok {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	exists, metadata, supply, owner, isWarp, frozen, err := storage.GetAsset(ctx, db, m.Asset)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetMissing}, nil
+	}
+	if isWarp {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWarpAsset}, nil
+	}
+	if owner != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOwner}, nil
+	}
+	if frozen {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetFrozen}, nil
+	}
+	exists, meterKey, capacity, meterOwner, technology, region, lastEnd, lastReading, err := storage.GetMeter(
+		ctx,
+		db,
+		m.Meter,
+	)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMeterMissing}, nil
+	}
+	// Readings only say which meter signed them, so a meter can only mint
+	// into assets of its owner. After a key rotation the meter must be moved
+	// with [TransferMeter].
+	if meterOwner != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOwner}, nil
+	}
+	if m.End <= m.Start {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInvalidInterval}, nil
+	}
+	if m.Start < lastEnd {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputIntervalOverlap}, nil
+	}
+	if m.End > t {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputReadingInFuture}, nil
+	}
+	if !crypto.Verify(ReadingMessage(m.Meter, m.Start, m.End, m.Reading), meterKey, m.Signature) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInvalidMeterSignature}, nil
+	}
+	if m.Reading < lastReading {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputReadingDecreased}, nil
+	}
+
+	// The meter cannot have produced more than its capacity for the whole
+	// interval: (reading - lastReading) Wh <= capacity W * interval s / 3600.
+	produced, err := smath.Mul64(m.Reading-lastReading, secondsPerHour)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	limit, err := smath.Mul64(capacity, uint64(m.End-m.Start))
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if produced > limit {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputExceedsCapacity}, nil
+	}
+	if uint64(m.Certificates) > m.Reading/whPerMWh-lastReading/whPerMWh {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputTooManyCertificates}, nil
+	}
+	if err := storage.SetMeter(
+		ctx, db, m.Meter, meterKey, capacity, meterOwner,
+		technology, region, m.End, m.Reading,
+	); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	for i := uint8(0); i < m.Certificates; i++ {
+		if err := storage.SetCertificate(
+			ctx, db, CertificateID(txID, i), m.Meter, technology, region,
+			m.Start, m.End, m.To, false, nil,
+		); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+
+	// Mint whole kWh by the change in the meter's kWh counter so that Wh that
+	// are not yet a full kWh are credited by a later reading.
+	value := m.Reading/whPerKWh - lastReading/whPerKWh
+	if value == 0 {
+		return &chain.Result{Success: true, Units: unitsUsed}, nil
+	}
+	newSupply, err := smath.Add64(supply, value)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.SetAsset(ctx, db, m.Asset, metadata, newSupply, owner, isWarp, false); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.AddBalance(ctx, db, m.To, m.Asset, value); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (m *ProduceMeteredEnergy) MaxUnits(chain.Rules) uint64 {
+	return crypto.PublicKeyLen + consts.IDLen*2 + consts.Uint64Len*3 + crypto.SignatureLen + 1 +
+		uint64(m.Certificates)*certificateUnits
+}
+
+func (m *ProduceMeteredEnergy) Marshal(p *codec.Packer) {
+	p.PackPublicKey(m.To)
+	p.PackID(m.Asset)
+	p.PackID(m.Meter)
+	p.PackInt64(m.Start)
+	p.PackInt64(m.End)
+	p.PackUint64(m.Reading)
+	p.PackSignature(m.Signature)
+	p.PackByte(m.Certificates)
+}
+
+func UnmarshalProduceMeteredEnergy(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var produce ProduceMeteredEnergy
+	p.UnpackPublicKey(false, &produce.To) // can produce to blackhole
+	p.UnpackID(true, &produce.Asset)      // cannot produce native asset
+	p.UnpackID(true, &produce.Meter)
+	produce.Start = p.UnpackInt64(false)
+	produce.End = p.UnpackInt64(true)
+	produce.Reading = p.UnpackUint64(false)
+	p.UnpackSignature(&produce.Signature)
+	produce.Certificates = p.UnpackByte()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if produce.Certificates > MaxCertificatesPerTx {
+		return nil, ErrTooManyCertificates
+	}
+	return &produce, nil
+}
+
+func (*ProduceMeteredEnergy) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
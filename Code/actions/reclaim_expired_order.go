@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*ReclaimExpiredOrder)(nil)
+
+// ReclaimExpiredOrder removes an expired order and refunds what is left of
+// its supply to the owner. Anyone can submit it.
+type ReclaimExpiredOrder struct {
+	// [Order] is the OrderID you wish to reclaim.
+	Order ids.ID `json:"order"`
+
+	// [Owner] is the owner of the order and the recipient of the refund.
+	Owner crypto.PublicKey `json:"owner"`
+
+	// [Out] is the asset locked up in the order. We need to provide this to
+	// populate [StateKeys].
+	Out ids.ID `json:"out"`
+}
+
+func (c *ReclaimExpiredOrder) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixEnergyOrderKey(c.Order),
+		storage.PrefixBalanceKey(c.Owner, c.Out),
+	}
+}
+
+func (c *ReclaimExpiredOrder) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	_ chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	unitsUsed := c.MaxUnits(r) // max units == units
+	exists, _, _, _, out, _, remaining, owner, expiry, _, _, err := storage.GetEnergyOrder(ctx, db, c.Order)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOrderMissing}, nil
+	}
+	if owner != c.Owner {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOwner}, nil
+	}
+	if out != c.Out {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOut}, nil
+	}
+	if !Expired(expiry, t) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOrderNotExpired}, nil
+	}
+	if err := storage.DeleteOrder(ctx, db, c.Order); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.AddBalance(ctx, db, owner, c.Out, remaining); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*ReclaimExpiredOrder) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen*2 + crypto.PublicKeyLen
+}
+
+func (c *ReclaimExpiredOrder) Marshal(p *codec.Packer) {
+	p.PackID(c.Order)
+	p.PackPublicKey(c.Owner)
+	p.PackID(c.Out)
+}
+
+func UnmarshalReclaimExpiredOrder(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var reclaim ReclaimExpiredOrder
+	p.UnpackID(true, &reclaim.Order)
+	p.UnpackPublicKey(true, &reclaim.Owner)
+	p.UnpackID(false, &reclaim.Out) // empty ID is the native asset
+	return &reclaim, p.Err()
+}
+
+func (*ReclaimExpiredOrder) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*RegisterMeter)(nil)
+
+type RegisterMeter struct {
+	// Meter is the key the meter signs its readings with.
+	Meter crypto.PublicKey `json:"meter"`
+
+	// Capacity is the nameplate capacity of the generator behind the meter in
+	// watts.
+	Capacity uint64 `json:"capacity"`
+
+	// Reading is the cumulative Wh shown on the meter at registration. Only
+	// energy produced after this reading can be minted.
+	Reading uint64 `json:"reading"`
+
+	// Technology and Region describe the generator and are recorded on every
+	// certificate it issues.
+	Technology []byte `json:"technology"`
+	Region     []byte `json:"region"`
+}
+
+func (*RegisterMeter) StateKeys(rauth chain.Auth, txID ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixMeterKey(txID),
+		storage.PrefixRoleKey(auth.GetActor(rauth)),
+	}
+}
+
+func (m *RegisterMeter) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	txID ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := m.MaxUnits(r)
+	if m.Capacity == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputCapacityZero}, nil
+	}
+	if len(m.Technology) > MaxCertificateFieldSize || len(m.Region) > MaxCertificateFieldSize {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMetadataTooLarge}, nil
+	}
+	if ok, err := hasRole(ctx, r, db, actor, storage.RoleProducer); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	} else if !ok {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	// The meter is owned by the account that registers it and is referenced
+	// by the [txID] of the registration.
+	if err := storage.SetMeter(
+		ctx, db, txID, m.Meter, m.Capacity,
+		actor, m.Technology, m.Region, 0, m.Reading,
+	); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (m *RegisterMeter) MaxUnits(chain.Rules) uint64 {
+	return crypto.PublicKeyLen + consts.Uint64Len*2 + uint64(len(m.Technology)+len(m.Region))
+}
+
+func (m *RegisterMeter) Marshal(p *codec.Packer) {
+	p.PackPublicKey(m.Meter)
+	p.PackUint64(m.Capacity)
+	p.PackUint64(m.Reading)
+	p.PackBytes(m.Technology)
+	p.PackBytes(m.Region)
+}
+
+func UnmarshalRegisterMeter(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var register RegisterMeter
+	p.UnpackPublicKey(true, &register.Meter)
+	register.Capacity = p.UnpackUint64(true)
+	register.Reading = p.UnpackUint64(false)
+	p.UnpackBytes(MaxCertificateFieldSize, false, &register.Technology)
+	p.UnpackBytes(MaxCertificateFieldSize, false, &register.Region)
+	return &register, p.Err()
+}
+
+func (*RegisterMeter) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	nconsts "github.com/bbehrman10/energyavavm/consts"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*RegisterSymbol)(nil)
+
+// RegisterSymbol reserves [Symbol] for [Asset] so it can be referred to by
+// ticker instead of ID. A symbol can only be registered once and an asset
+// can only have one symbol.
+type RegisterSymbol struct {
+	// [Asset] is the asset to register [Symbol] for.
+	Asset ids.ID `json:"asset"`
+
+	// [Symbol] is 1-[MaxSymbolSize] uppercase letters and digits.
+	Symbol string `json:"symbol"`
+}
+
+func (s *RegisterSymbol) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixAssetKey(s.Asset),
+		storage.PrefixSymbolKey(s.Symbol),
+		storage.PrefixAssetSymbolKey(s.Asset),
+	}
+}
+
+func (s *RegisterSymbol) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := s.MaxUnits(r)
+	if err := VerifySymbol(s.Symbol); err != nil {
+		// This should be guarded via [Unmarshal] but we check anyways.
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInvalidSymbol}, nil
+	}
+	if _, _, _, failure := getOwnedAsset(ctx, db, s.Asset, actor); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if s.Symbol == nconsts.Symbol {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSymbolTaken}, nil
+	}
+	exists, _, err := storage.GetSymbolAsset(ctx, db, s.Symbol)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSymbolTaken}, nil
+	}
+	exists, _, err = storage.GetAssetSymbol(ctx, db, s.Asset)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputAssetHasSymbol}, nil
+	}
+	if err := storage.SetSymbol(ctx, db, s.Symbol, s.Asset); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (s *RegisterSymbol) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen + uint64(len(s.Symbol))
+}
+
+func (s *RegisterSymbol) Marshal(p *codec.Packer) {
+	p.PackID(s.Asset)
+	p.PackString(s.Symbol)
+}
+
+func UnmarshalRegisterSymbol(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var register RegisterSymbol
+	p.UnpackID(true, &register.Asset) // cannot register native asset
+	register.Symbol = p.UnpackString(true)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if err := VerifySymbol(register.Symbol); err != nil {
+		return nil, err
+	}
+	return &register, nil
+}
+
+func (*RegisterSymbol) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*RetireCertificate)(nil)
+
+type RetireCertificate struct {
+	// Certificate is the ID of the certificate to retire.
+	Certificate ids.ID `json:"certificate"`
+
+	// Beneficiary names who the green energy claim is made for. Once set, a
+	// retired certificate can no longer be transferred or retired again.
+	Beneficiary []byte `json:"beneficiary"`
+}
+
+func (c *RetireCertificate) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixCertificateKey(c.Certificate)}
+}
+
+func (c *RetireCertificate) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := c.MaxUnits(r)
+	if len(c.Beneficiary) > MaxCertificateFieldSize {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMetadataTooLarge}, nil
+	}
+	exists, meter, technology, region, start, end, owner, retired, _, err := storage.GetCertificate(
+		ctx,
+		db,
+		c.Certificate,
+	)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputCertificateMissing}, nil
+	}
+	if owner != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if retired {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputCertificateRetired}, nil
+	}
+	if err := storage.SetCertificate(
+		ctx, db, c.Certificate, meter, technology, region,
+		start, end, owner, true, c.Beneficiary,
+	); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (c *RetireCertificate) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen + uint64(len(c.Beneficiary))
+}
+
+func (c *RetireCertificate) Marshal(p *codec.Packer) {
+	p.PackID(c.Certificate)
+	p.PackBytes(c.Beneficiary)
+}
+
+func UnmarshalRetireCertificate(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var retire RetireCertificate
+	p.UnpackID(true, &retire.Certificate)
+	p.UnpackBytes(MaxCertificateFieldSize, true, &retire.Beneficiary)
+	return &retire, p.Err()
+}
+
+func (*RetireCertificate) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*RevokeRole)(nil)
+
+// RevokeRole takes [Role] from [From]. Only operators can revoke roles.
+type RevokeRole struct {
+	// [From] is the account that loses [Role].
+	From crypto.PublicKey `json:"from"`
+
+	// [Role] is a single one of the roles defined in storage.
+	Role uint8 `json:"role"`
+}
+
+func (v *RevokeRole) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixRoleKey(auth.GetActor(rauth)),
+		storage.PrefixRoleKey(v.From),
+	}
+}
+
+func (v *RevokeRole) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := v.MaxUnits(r)
+	if !ValidRole(v.Role) {
+		// This should be guarded via [Unmarshal] but we check anyways.
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInvalidRole}, nil
+	}
+	actorRoles, err := storage.GetRoles(ctx, db, actor)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if actorRoles&storage.RoleOperator == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if v.Role == storage.RoleOperator && v.From == actor {
+		// Operators can only be removed by another operator, so there is
+		// always one left to manage roles
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSelfRevoke}, nil
+	}
+	roles, err := storage.GetRoles(ctx, db, v.From)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if roles&v.Role == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputRoleMissing}, nil
+	}
+	if err := storage.SetRoles(ctx, db, v.From, roles&^v.Role); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*RevokeRole) MaxUnits(chain.Rules) uint64 {
+	return crypto.PublicKeyLen + 1
+}
+
+func (v *RevokeRole) Marshal(p *codec.Packer) {
+	p.PackPublicKey(v.From)
+	p.PackByte(v.Role)
+}
+
+func UnmarshalRevokeRole(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var revoke RevokeRole
+	p.UnpackPublicKey(true, &revoke.From)
+	revoke.Role = p.UnpackByte()
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if !ValidRole(revoke.Role) {
+		return nil, ErrInvalidRole
+	}
+	return &revoke, nil
+}
+
+func (*RevokeRole) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
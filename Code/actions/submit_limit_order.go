@@ -0,0 +1,439 @@
+package actions
+
+import (
+	"context"
+	"math/bits"
+	"sort"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*SubmitLimitOrder)(nil)
+
+// OrderMatch is a resting order an action may fill.
+type OrderMatch struct {
+	// [Order] is the OrderID of the resting order.
+	Order ids.ID `json:"order"`
+
+	// [Owner] is the owner of the resting order. We need to provide this to
+	// populate [StateKeys].
+	Owner crypto.PublicKey `json:"owner"`
+}
+
+// SubmitLimitOrder crosses the resting orders in [Matches] at their own
+// price and lists whatever is left of [Supply] as a new order.
+type SubmitLimitOrder struct {
+	// Side is either [SideAsk] or [SideBid]. It must be the [OrderSide] of
+	// [In] and [Out].
+	Side uint8 `json:"side"`
+
+	// In is the asset received for [Out].
+	In ids.ID `json:"in"`
+
+	// InTick and OutTick are the limit price: at least [InTick] of [In] must
+	// be received for every [OutTick] of [Out].
+	InTick  uint64 `json:"inTick"`
+	Out     ids.ID `json:"out"`
+	OutTick uint64 `json:"outTick"`
+
+	// Supply is the total amount of [Out] to trade.
+	Supply uint64 `json:"supply"`
+
+	// Expiry of the listed order, if any is left. 0 means it never expires.
+	Expiry int64 `json:"expiry"`
+
+	// Matches are resting orders on the other side of the book that may be
+	// crossed. They are filled in price priority, regardless of the order
+	// they are provided in. Orders that were filled, closed or expired since
+	// the tx was issued are skipped, as are orders whose flags don't allow
+	// the fill.
+	Matches []*OrderMatch `json:"matches"`
+
+	// Treasury is the account that collects the trading fees set in genesis
+	// on every match. We need to provide this to populate [StateKeys].
+	Treasury crypto.PublicKey `json:"treasury"`
+}
+
+func (s *SubmitLimitOrder) StateKeys(rauth chain.Auth, txID ids.ID) [][]byte {
+	actor := auth.GetActor(rauth)
+	keys := make([][]byte, 0, 8+len(s.Matches)*2)
+	keys = append(
+		keys,
+		storage.PrefixBalanceKey(actor, s.Out),
+		storage.PrefixBalanceKey(actor, s.In),
+		storage.PrefixBalanceKey(s.Treasury, s.Out),
+		storage.PrefixBalanceKey(s.Treasury, s.In),
+		storage.PrefixEnergyOrderKey(txID),
+		storage.PrefixRoleKey(actor),
+	)
+	keys = append(keys, frozenKeys(s.In, s.Out)...)
+	for _, m := range s.Matches {
+		keys = append(
+			keys,
+			storage.PrefixEnergyOrderKey(m.Order),
+			storage.PrefixBalanceKey(m.Owner, s.Out),
+		)
+	}
+	return keys
+}
+
+func packOrderMatches(p *codec.Packer, matches []*OrderMatch) {
+	p.PackInt(len(matches))
+	for _, m := range matches {
+		p.PackID(m.Order)
+		p.PackPublicKey(m.Owner)
+	}
+}
+
+func unpackOrderMatches(p *codec.Packer, required bool) ([]*OrderMatch, error) {
+	count := p.UnpackInt(required)
+	if count > MaxOrderMatches {
+		return nil, chain.ErrInvalidObject
+	}
+	var matches []*OrderMatch
+	seen := set.NewSet[ids.ID](count)
+	for i := 0; i < count; i++ {
+		var m OrderMatch
+		p.UnpackID(true, &m.Order)
+		p.UnpackPublicKey(true, &m.Owner)
+		if seen.Contains(m.Order) {
+			return nil, chain.ErrInvalidObject
+		}
+		seen.Add(m.Order)
+		matches = append(matches, &m)
+	}
+	return matches, nil
+}
+
+// restingOrder is a matched order loaded from state.
+type restingOrder struct {
+	id        ids.ID
+	side      uint8
+	owner     crypto.PublicKey
+	inTick    uint64
+	outTick   uint64
+	remaining uint64
+	expiry    int64
+	allOrNone bool
+	minFill   uint64
+}
+
+func (s *SubmitLimitOrder) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	rauth chain.Auth,
+	txID ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := s.MaxUnits(r) // max units == units
+	if s.In == s.Out {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSameInOut}, nil
+	}
+	if s.Side != OrderSide(s.In, s.Out) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSideMismatch}, nil
+	}
+	if s.InTick == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInTickZero}, nil
+	}
+	if s.OutTick == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOutTickZero}, nil
+	}
+	if s.Supply == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSupplyZero}, nil
+	}
+	if s.Supply%s.OutTick != 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSupplyMisaligned}, nil
+	}
+	if Expired(s.Expiry, t) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputOrderExpired}, nil
+	}
+	if ok, err := hasRole(ctx, r, db, actor, marketRoles); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	} else if !ok {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if failure := checkNotFrozen(ctx, db, s.In, s.Out); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	makerFeeBips, takerFeeBips, treasury := tradingFees(r)
+	if treasury != s.Treasury {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongTreasury}, nil
+	}
+	if err := storage.SubBalance(ctx, db, actor, s.Out, s.Supply); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	// Load the resting orders that can still be filled
+	resting := make([]*restingOrder, 0, len(s.Matches))
+	for _, m := range s.Matches {
+		exists, side, in, inTick, out, outTick, remaining, owner, expiry, allOrNone, minFill, err := storage.GetEnergyOrder(ctx, db, m.Order)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if !exists || Expired(expiry, t) {
+			continue
+		}
+		if owner != m.Owner {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOwner}, nil
+		}
+		if side == s.Side {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongSide}, nil
+		}
+		if in != s.Out {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongIn}, nil
+		}
+		if out != s.In {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOut}, nil
+		}
+		resting = append(resting, &restingOrder{
+			m.Order, side, owner, inTick, outTick,
+			remaining, expiry, allOrNone, minFill,
+		})
+	}
+
+	// Fill the orders that give the most [In] per [Out] first
+	sort.SliceStable(resting, func(i, j int) bool {
+		return compareRates(resting[i].outTick, resting[i].inTick, resting[j].outTick, resting[j].inTick) > 0
+	})
+	var (
+		left  = s.Supply
+		fills = make([]*OrderFill, 0, len(resting))
+	)
+	for _, o := range resting {
+		if compareRates(o.outTick, o.inTick, s.InTick, s.OutTick) < 0 {
+			// All remaining orders are priced worse than the limit
+			break
+		}
+		value := left - left%o.inTick
+		if value == 0 {
+			continue
+		}
+		inputAmount, outputAmount, orderRemaining, failure := trade(o.inTick, o.outTick, o.remaining, value)
+		if failure != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+		}
+		if checkMakerFlags(o.allOrNone, o.minFill, outputAmount, o.remaining) != nil {
+			// The owner doesn't allow a fill of this size
+			continue
+		}
+		makerFee := feeAmount(inputAmount, makerFeeBips)
+		takerFee := feeAmount(outputAmount, takerFeeBips)
+		if err := storage.AddBalance(ctx, db, o.owner, s.Out, inputAmount-makerFee); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if err := storage.AddBalance(ctx, db, actor, s.In, outputAmount-takerFee); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if makerFee > 0 {
+			if err := storage.AddBalance(ctx, db, s.Treasury, s.Out, makerFee); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		}
+		if takerFee > 0 {
+			if err := storage.AddBalance(ctx, db, s.Treasury, s.In, takerFee); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		}
+		if orderRemaining == 0 {
+			if err := storage.DeleteOrder(ctx, db, o.id); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		} else {
+			if err := storage.SetEnergyOrder(
+				ctx, db, o.id, o.side, s.Out, o.inTick,
+				s.In, o.outTick, orderRemaining, o.owner, o.expiry,
+				o.allOrNone, o.minFill,
+			); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+		}
+		left -= inputAmount
+		fills = append(fills, &OrderFill{
+			o.id, inputAmount, outputAmount - takerFee,
+			orderRemaining, makerFee, takerFee,
+		})
+	}
+
+	// List what is left at the limit price. Anything that doesn't fit a whole
+	// [OutTick] is refunded.
+	listed := left - left%s.OutTick
+	if refund := left - listed; refund > 0 {
+		if err := storage.AddBalance(ctx, db, actor, s.Out, refund); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+	if listed > 0 {
+		if err := storage.SetEnergyOrder(
+			ctx, db, txID, s.Side, s.In, s.InTick,
+			s.Out, s.OutTick, listed, actor, s.Expiry,
+			false, 0,
+		); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+	}
+	lr := &LimitOrderResult{Fills: fills, Listed: listed}
+	output, err := lr.Marshal()
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed, Output: output}, nil
+}
+
+func (s *SubmitLimitOrder) MaxUnits(chain.Rules) uint64 {
+	return 1 + consts.IDLen*2 + consts.Uint64Len*4 + crypto.PublicKeyLen +
+		uint64(len(s.Matches))*(consts.IDLen+crypto.PublicKeyLen+tradeSucceededPrice)
+}
+
+func (s *SubmitLimitOrder) Marshal(p *codec.Packer) {
+	p.PackByte(s.Side)
+	p.PackID(s.In)
+	p.PackUint64(s.InTick)
+	p.PackID(s.Out)
+	p.PackUint64(s.OutTick)
+	p.PackUint64(s.Supply)
+	p.PackInt64(s.Expiry)
+	packOrderMatches(p, s.Matches)
+	p.PackPublicKey(s.Treasury)
+}
+
+func UnmarshalSubmitLimitOrder(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var limit SubmitLimitOrder
+	limit.Side = p.UnpackByte()
+	p.UnpackID(false, &limit.In)
+	limit.InTick = p.UnpackUint64(true)
+	p.UnpackID(false, &limit.Out)
+	limit.OutTick = p.UnpackUint64(true)
+	limit.Supply = p.UnpackUint64(true)
+	limit.Expiry = p.UnpackInt64(false) // 0 means no expiry
+	matches, err := unpackOrderMatches(p, false)
+	if err != nil {
+		return nil, err
+	}
+	limit.Matches = matches
+	p.UnpackPublicKey(false, &limit.Treasury) // empty if there are no fees
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if limit.Side > SideBid || limit.Expiry < 0 {
+		return nil, chain.ErrInvalidObject
+	}
+	return &limit, nil
+}
+
+func (*SubmitLimitOrder) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+// RestingOrder returns the order listed by a successful [SubmitLimitOrder]
+// with [listed] supply left after matching.
+func (s *SubmitLimitOrder) RestingOrder(listed uint64) *CreateEnergyOrder {
+	return &CreateEnergyOrder{
+		Side:    s.Side,
+		In:      s.In,
+		InTick:  s.InTick,
+		Out:     s.Out,
+		OutTick: s.OutTick,
+		Supply:  listed,
+		Expiry:  s.Expiry,
+	}
+}
+
+// compareRates returns -1, 0 or 1 if [aNum]/[aDen] is less than, equal to or
+// greater than [bNum]/[bDen]. The products are compared as 128-bit values so
+// they can't overflow.
+func compareRates(aNum uint64, aDen uint64, bNum uint64, bDen uint64) int {
+	lhsHi, lhsLo := bits.Mul64(aNum, bDen)
+	rhsHi, rhsLo := bits.Mul64(bNum, aDen)
+	switch {
+	case lhsHi < rhsHi || (lhsHi == rhsHi && lhsLo < rhsLo):
+		return -1
+	case lhsHi == rhsHi && lhsLo == rhsLo:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// OrderFill describes a single fill of a resting order. [Out] is what the
+// taker received after the [TakerFee] and the owner of the order received
+// [In] less the [MakerFee].
+type OrderFill struct {
+	Order     ids.ID `json:"order"`
+	In        uint64 `json:"in"`
+	Out       uint64 `json:"out"`
+	Remaining uint64 `json:"remaining"`
+	MakerFee  uint64 `json:"makerFee"`
+	TakerFee  uint64 `json:"takerFee"`
+}
+
+// LimitOrderResult is the receipt of a successful [SubmitLimitOrder]. If
+// [Listed] is non-zero, a new order with the ID of the tx was listed with
+// that supply.
+type LimitOrderResult struct {
+	Fills  []*OrderFill `json:"fills"`
+	Listed uint64       `json:"listed"`
+}
+
+const orderFillLen = consts.IDLen + consts.Uint64Len*5
+
+func UnmarshalLimitOrderResult(b []byte) (*LimitOrderResult, error) {
+	p := codec.NewReader(b, consts.IntLen+MaxOrderMatches*orderFillLen+consts.Uint64Len)
+	var result LimitOrderResult
+	fills, err := unpackOrderFills(p, false)
+	if err != nil {
+		return nil, err
+	}
+	result.Fills = fills
+	result.Listed = p.UnpackUint64(false)
+	return &result, p.Err()
+}
+
+func (l *LimitOrderResult) Marshal() ([]byte, error) {
+	p := codec.NewWriter(consts.IntLen + len(l.Fills)*orderFillLen + consts.Uint64Len)
+	packOrderFills(p, l.Fills)
+	p.PackUint64(l.Listed)
+	return p.Bytes(), p.Err()
+}
+
+func packOrderFills(p *codec.Packer, fills []*OrderFill) {
+	p.PackInt(len(fills))
+	for _, fill := range fills {
+		p.PackID(fill.Order)
+		p.PackUint64(fill.In)
+		p.PackUint64(fill.Out)
+		p.PackUint64(fill.Remaining)
+		p.PackUint64(fill.MakerFee)
+		p.PackUint64(fill.TakerFee)
+	}
+}
+
+func unpackOrderFills(p *codec.Packer, required bool) ([]*OrderFill, error) {
+	count := p.UnpackInt(required)
+	if count > MaxOrderMatches {
+		return nil, chain.ErrInvalidObject
+	}
+	fills := make([]*OrderFill, count)
+	for i := range fills {
+		var fill OrderFill
+		p.UnpackID(true, &fill.Order)
+		fill.In = p.UnpackUint64(true)
+		fill.Out = p.UnpackUint64(false)       // can be 0 if the taker fee is 100%
+		fill.Remaining = p.UnpackUint64(false) // if 0, delete
+		fill.MakerFee = p.UnpackUint64(false)
+		fill.TakerFee = p.UnpackUint64(false)
+		fills[i] = &fill
+	}
+	return fills, nil
+}
@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*TransferCertificate)(nil)
+
+type TransferCertificate struct {
+	// Certificate is the ID of the certificate to transfer.
+	Certificate ids.ID `json:"certificate"`
+
+	// To is the new owner of [Certificate].
+	To crypto.PublicKey `json:"to"`
+}
+
+func (t *TransferCertificate) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixCertificateKey(t.Certificate)}
+}
+
+func (t *TransferCertificate) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := t.MaxUnits(r)
+	exists, meter, technology, region, start, end, owner, retired, beneficiary, err := storage.GetCertificate(
+		ctx,
+		db,
+		t.Certificate,
+	)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputCertificateMissing}, nil
+	}
+	if owner != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputUnauthorized}, nil
+	}
+	if retired {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputCertificateRetired}, nil
+	}
+	if err := storage.SetCertificate(
+		ctx, db, t.Certificate, meter, technology, region,
+		start, end, t.To, retired, beneficiary,
+	); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*TransferCertificate) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen + crypto.PublicKeyLen
+}
+
+func (t *TransferCertificate) Marshal(p *codec.Packer) {
+	p.PackID(t.Certificate)
+	p.PackPublicKey(t.To)
+}
+
+func UnmarshalTransferCertificate(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var transfer TransferCertificate
+	p.UnpackID(true, &transfer.Certificate)
+	p.UnpackPublicKey(false, &transfer.To) // can transfer to blackhole
+	return &transfer, p.Err()
+}
+
+func (*TransferCertificate) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,96 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*TransferEnergy)(nil)
+
+type TransferEnergy struct {
+	// To is the recipient of the [Value].
+	To crypto.PublicKey `json:"to"`
+
+	// Asset to transfer to [To]. ids.Empty is the native token.
+	Asset ids.ID `json:"asset"`
+
+	// Amount of [Asset] transferred to [To].
+	Value uint64 `json:"value"`
+
+	// Memo is an optional note attached to the transfer.
+	Memo []byte `json:"memo"`
+}
+
+func (t *TransferEnergy) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	// The asset key is only read to check that the asset is not frozen.
+	return append(
+		[][]byte{
+			storage.PrefixBalanceKey(auth.GetActor(rauth), t.Asset),
+			storage.PrefixBalanceKey(t.To, t.Asset),
+		},
+		frozenKeys(t.Asset)...,
+	)
+}
+
+func (t *TransferEnergy) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := t.MaxUnits(r)
+	if t.Value == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+	if len(t.Memo) > MaxMetadataSize {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMemoTooLarge}, nil
+	}
+	if failure := checkNotFrozen(ctx, db, t.Asset); failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if err := storage.SubBalance(ctx, db, actor, t.Asset, t.Value); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.AddBalance(ctx, db, t.To, t.Asset, t.Value); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (t *TransferEnergy) MaxUnits(chain.Rules) uint64 {
+	return crypto.PublicKeyLen + consts.IDLen + consts.Uint64Len + uint64(len(t.Memo))
+}
+
+func (t *TransferEnergy) Marshal(p *codec.Packer) {
+	p.PackPublicKey(t.To)
+	p.PackID(t.Asset)
+	p.PackUint64(t.Value)
+	p.PackBytes(t.Memo)
+}
+
+func UnmarshalTransferEnergy(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var transfer TransferEnergy
+	p.UnpackPublicKey(false, &transfer.To) // can transfer to blackhole
+	p.UnpackID(false, &transfer.Asset)     // empty ID is the native asset
+	transfer.Value = p.UnpackUint64(true)
+	p.UnpackBytes(MaxMetadataSize, false, &transfer.Memo)
+	return &transfer, p.Err()
+}
+
+func (*TransferEnergy) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,86 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*TransferMeter)(nil)
+
+// TransferMeter hands a meter to another account, for example after
+// [UpdateAssetOwner] rotated the key of the asset the meter mints into.
+type TransferMeter struct {
+	// Meter is the [TxID] that registered the meter.
+	Meter ids.ID `json:"meter"`
+
+	// To is the account that can mint the meter's readings from now on.
+	To crypto.PublicKey `json:"to"`
+}
+
+func (t *TransferMeter) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixMeterKey(t.Meter)}
+}
+
+func (t *TransferMeter) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := t.MaxUnits(r)
+	exists, meterKey, capacity, owner, technology, region, lastEnd, lastReading, err := storage.GetMeter(
+		ctx,
+		db,
+		t.Meter,
+	)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMeterMissing}, nil
+	}
+	if owner != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWrongOwner}, nil
+	}
+	if err := storage.SetMeter(
+		ctx, db, t.Meter, meterKey, capacity, t.To,
+		technology, region, lastEnd, lastReading,
+	); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*TransferMeter) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen + crypto.PublicKeyLen
+}
+
+func (t *TransferMeter) Marshal(p *codec.Packer) {
+	p.PackID(t.Meter)
+	p.PackPublicKey(t.To)
+}
+
+func UnmarshalTransferMeter(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var transfer TransferMeter
+	p.UnpackID(true, &transfer.Meter)
+	p.UnpackPublicKey(true, &transfer.To) // cannot hand meter to nothing
+	return &transfer, p.Err()
+}
+
+func (*TransferMeter) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
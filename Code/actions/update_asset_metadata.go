@@ -0,0 +1,79 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*UpdateAssetMetadata)(nil)
+
+// UpdateAssetMetadata replaces the metadata of [Asset]. Only the owner of
+// [Asset] can update it.
+type UpdateAssetMetadata struct {
+	// [Asset] is the asset to update.
+	Asset ids.ID `json:"asset"`
+
+	// [Metadata] replaces the metadata set when [Asset] was initialized. It
+	// must be an encoded [AssetMetadata].
+	Metadata []byte `json:"metadata"`
+}
+
+func (u *UpdateAssetMetadata) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixAssetKey(u.Asset)}
+}
+
+func (u *UpdateAssetMetadata) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := u.MaxUnits(r)
+	if len(u.Metadata) > MaxMetadataSize {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMetadataTooLarge}, nil
+	}
+	metadata, failure := parseAssetMetadata(u.Metadata)
+	if failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	_, supply, frozen, failure := getOwnedAsset(ctx, db, u.Asset, actor)
+	if failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if err := storage.SetAsset(ctx, db, u.Asset, metadata, supply, actor, false, frozen); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (u *UpdateAssetMetadata) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen + uint64(len(u.Metadata))
+}
+
+func (u *UpdateAssetMetadata) Marshal(p *codec.Packer) {
+	p.PackID(u.Asset)
+	p.PackBytes(u.Metadata)
+}
+
+func UnmarshalUpdateAssetMetadata(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var update UpdateAssetMetadata
+	p.UnpackID(true, &update.Asset) // cannot update native asset
+	p.UnpackBytes(MaxMetadataSize, false, &update.Metadata)
+	return &update, p.Err()
+}
+
+func (*UpdateAssetMetadata) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
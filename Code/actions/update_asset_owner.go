@@ -0,0 +1,101 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+var _ chain.Action = (*UpdateAssetOwner)(nil)
+
+// UpdateAssetOwner hands control of [Asset] to [Owner], for example when the
+// key of a utility rotates.
+type UpdateAssetOwner struct {
+	// [Asset] is the asset to update.
+	Asset ids.ID `json:"asset"`
+
+	// [Owner] is the account that can produce and administer [Asset] from
+	// now on.
+	Owner crypto.PublicKey `json:"owner"`
+}
+
+func (u *UpdateAssetOwner) StateKeys(chain.Auth, ids.ID) [][]byte {
+	return [][]byte{storage.PrefixAssetKey(u.Asset)}
+}
+
+func (u *UpdateAssetOwner) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := u.MaxUnits(r)
+	metadata, supply, frozen, failure := getOwnedAsset(ctx, db, u.Asset, actor)
+	if failure != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: failure}, nil
+	}
+	if err := storage.SetAsset(ctx, db, u.Asset, metadata, supply, u.Owner, false, frozen); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*UpdateAssetOwner) MaxUnits(chain.Rules) uint64 {
+	return consts.IDLen + crypto.PublicKeyLen
+}
+
+func (u *UpdateAssetOwner) Marshal(p *codec.Packer) {
+	p.PackID(u.Asset)
+	p.PackPublicKey(u.Owner)
+}
+
+func UnmarshalUpdateAssetOwner(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var update UpdateAssetOwner
+	p.UnpackID(true, &update.Asset)        // cannot update native asset
+	p.UnpackPublicKey(true, &update.Owner) // cannot hand asset to nothing
+	return &update, p.Err()
+}
+
+func (*UpdateAssetOwner) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+// getOwnedAsset loads [asset] for an administrative change by [actor]. If
+// [actor] can't administer [asset], [failure] is set to the output of the
+// failed action.
+func getOwnedAsset(
+	ctx context.Context,
+	db chain.Database,
+	asset ids.ID,
+	actor crypto.PublicKey,
+) (metadata []byte, supply uint64, frozen bool, failure []byte) {
+	if asset == ids.Empty {
+		return nil, 0, false, OutputAssetIsNative
+	}
+	exists, metadata, supply, owner, isWarp, frozen, err := storage.GetAsset(ctx, db, asset)
+	if err != nil {
+		return nil, 0, false, utils.ErrBytes(err)
+	}
+	if !exists {
+		return nil, 0, false, OutputAssetMissing
+	}
+	if isWarp {
+		return nil, 0, false, OutputWarpAsset
+	}
+	if owner != actor {
+		return nil, 0, false, OutputWrongOwner
+	}
+	return metadata, supply, frozen, nil
+}
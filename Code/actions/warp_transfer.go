@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+)
+
+const WarpTransferSize = crypto.PublicKeyLen + consts.IDLen + consts.Uint64Len + 1 + consts.IDLen
+
+// WarpTransfer is the payload of a warp message emitted by [ExportEnergy] and
+// consumed by [ImportEnergy].
+type WarpTransfer struct {
+	To    crypto.PublicKey `json:"to"`
+	Asset ids.ID           `json:"asset"`
+	Value uint64           `json:"value"`
+
+	// Return is set to true when a warp message is sending kWh back to the
+	// subnet where the asset was initialized.
+	Return bool `json:"return"`
+
+	// TxID is the transaction that created this message. This is used to ensure
+	// there is WarpID uniqueness.
+	TxID ids.ID `json:"txID"`
+}
+
+func (w *WarpTransfer) Marshal() ([]byte, error) {
+	p := codec.NewWriter(WarpTransferSize)
+	p.PackPublicKey(w.To)
+	p.PackID(w.Asset)
+	p.PackUint64(w.Value)
+	p.PackBool(w.Return)
+	p.PackID(w.TxID)
+	return p.Bytes(), p.Err()
+}
+
+func UnmarshalWarpTransfer(b []byte) (*WarpTransfer, error) {
+	var transfer WarpTransfer
+	p := codec.NewReader(b, WarpTransferSize)
+	p.UnpackPublicKey(false, &transfer.To)
+	p.UnpackID(false, &transfer.Asset)
+	transfer.Value = p.UnpackUint64(true)
+	transfer.Return = p.UnpackBool()
+	p.UnpackID(true, &transfer.TxID)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if !p.Empty() {
+		return nil, chain.ErrInvalidObject
+	}
+	return &transfer, nil
+}
+
+// ImportedAssetID is the ID of the warp asset minted on this subnet for
+// [assetID] initialized on [sourceChainID].
+func ImportedAssetID(assetID ids.ID, sourceChainID ids.ID) ids.ID {
+	return utils.ToID(ImportedAssetMetadata(assetID, sourceChainID))
+}
+
+// ImportedAssetMetadata is the metadata of an imported asset. It records where
+// the asset came from so it can only be returned to that subnet.
+func ImportedAssetMetadata(assetID ids.ID, sourceChainID ids.ID) []byte {
+	k := make([]byte, consts.IDLen*2)
+	copy(k, assetID[:])
+	copy(k[consts.IDLen:], sourceChainID[:])
+	return k
+}
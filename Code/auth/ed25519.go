@@ -143,5 +143,5 @@ type ED25519Factory struct {
 
 func (d *ED25519Factory) Sign(msg []byte, _ chain.Action) (chain.Auth, error) {
 	sig := crypto.Sign(msg, d.priv)
-	return &ED25519{d.priv.PublicKey(), sig}, nil
+	return &ED25519{Signer: d.priv.PublicKey(), Signature: sig}, nil
 }
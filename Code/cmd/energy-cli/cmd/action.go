@@ -0,0 +1,1239 @@
+//nolint:lll
+package cmd
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ava-labs/hypersdk/consts"
+	hutils "github.com/ava-labs/hypersdk/utils"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+var actionCmd = &cobra.Command{
+	Use: "action",
+	RunE: func(*cobra.Command, []string) error {
+		return ErrMissingSubcommand
+	},
+}
+
+var initializeAssetCmd = &cobra.Command{
+	Use: "initialize-asset",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Describe asset
+		metadata, err := promptAssetMetadata()
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		txID, success, _, err := sendAndWait(ctx, cli, &actions.InitializeEnergyAsset{
+			Metadata: metadata,
+		}, factory)
+		if err != nil {
+			return err
+		}
+		if success {
+			hutils.Outf("{{yellow}}assetID:{{/}} %s\n", txID)
+		}
+		return nil
+	},
+}
+
+var produceCmd = &cobra.Command{
+	Use: "produce",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset to produce
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		exists, metadata, supply, owner, warp, frozen, err := cli.Asset(ctx, assetID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			hutils.Outf("{{red}}%s does not exist{{/}}\n", assetID)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+		if warp {
+			hutils.Outf("{{red}}cannot produce a warped asset{{/}}\n")
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+		if owner != utils.Address(priv.PublicKey()) {
+			hutils.Outf("{{red}}%s is the owner of %s, you are not{{/}}\n", owner, assetID)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+		if frozen {
+			hutils.Outf("{{red}}%s is frozen{{/}}\n", assetID)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+		hutils.Outf(
+			"{{yellow}}metadata:{{/}} %s {{yellow}}supply:{{/}} %d\n",
+			metadataString(metadata),
+			supply,
+		)
+
+		// Select recipient
+		recipient, err := promptAddress("recipient")
+		if err != nil {
+			return err
+		}
+
+		// Select amount
+		amount, err := promptAmount("kWh", assetID, consts.MaxUint64-supply, nil)
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.ProduceEnergy{
+			To:    recipient,
+			Asset: assetID,
+			Value: amount,
+		}, factory)
+		return err
+	},
+}
+
+var consumeCmd = &cobra.Command{
+	Use: "consume",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset to consume
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		balance, err := getAssetInfo(ctx, cli, priv.PublicKey(), assetID, true)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		// Select amount
+		amount, err := promptAmount("kWh", assetID, balance, nil)
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.ConsumeEnergy{
+			Asset: assetID,
+			Value: amount,
+		}, factory)
+		return err
+	},
+}
+
+var transferCmd = &cobra.Command{
+	Use: "transfer",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset to transfer
+		assetID, err := promptAsset(ctx, cli, "assetID", true)
+		if err != nil {
+			return err
+		}
+		balance, err := getAssetInfo(ctx, cli, priv.PublicKey(), assetID, true)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		// Select recipient
+		recipient, err := promptAddress("recipient")
+		if err != nil {
+			return err
+		}
+
+		// Select amount
+		amount, err := promptAmount("amount", assetID, balance, nil)
+		if err != nil {
+			return err
+		}
+
+		// Add optional memo
+		promptText := promptui.Prompt{
+			Label: "memo (optional)",
+			Validate: func(input string) error {
+				if len(input) > actions.MaxMetadataSize {
+					return ErrInputTooLarge
+				}
+				return nil
+			},
+		}
+		memo, err := promptText.Run()
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.TransferEnergy{
+			To:    recipient,
+			Asset: assetID,
+			Value: amount,
+			Memo:  []byte(memo),
+		}, factory)
+		return err
+	},
+}
+
+var createOrderCmd = &cobra.Command{
+	Use: "create-order",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select inbound asset
+		inAssetID, err := promptAsset(ctx, cli, "in assetID", true)
+		if err != nil {
+			return err
+		}
+		if _, err := getAssetInfo(ctx, cli, priv.PublicKey(), inAssetID, false); err != nil {
+			return err
+		}
+
+		// Select in tick
+		inTick, err := promptAmount("in tick", inAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+		balance, err := getAssetInfo(ctx, cli, priv.PublicKey(), outAssetID, true)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		// Select out tick
+		outTick, err := promptAmount("out tick", outAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select supply
+		supply, err := promptAmount(
+			"supply (must be multiple of out tick)",
+			outAssetID,
+			balance,
+			func(input uint64) error {
+				if input%outTick != 0 {
+					return ErrNotMultiple
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// Select expiry
+		expiry, err := promptExpiry()
+		if err != nil {
+			return err
+		}
+
+		// Select fill restrictions
+		allOrNone, err := promptBool("all or none")
+		if err != nil {
+			return err
+		}
+		var minFill uint64
+		if !allOrNone {
+			minFill, err = promptAmount("min fill (0 for none)", outAssetID, supply, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		txID, success, _, err := sendAndWait(ctx, cli, &actions.CreateEnergyOrder{
+			Side:      actions.OrderSide(inAssetID, outAssetID),
+			In:        inAssetID,
+			InTick:    inTick,
+			Out:       outAssetID,
+			OutTick:   outTick,
+			Supply:    supply,
+			Expiry:    expiry,
+			AllOrNone: allOrNone,
+			MinFill:   minFill,
+		}, factory)
+		if err != nil {
+			return err
+		}
+		if success {
+			hutils.Outf("{{yellow}}orderID:{{/}} %s\n", txID)
+		}
+		return nil
+	},
+}
+
+var limitOrderCmd = &cobra.Command{
+	Use: "limit-order",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select inbound asset
+		inAssetID, err := promptAsset(ctx, cli, "in assetID", true)
+		if err != nil {
+			return err
+		}
+		if _, err := getAssetInfo(ctx, cli, priv.PublicKey(), inAssetID, false); err != nil {
+			return err
+		}
+
+		// Select in tick
+		inTick, err := promptAmount("in tick", inAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+		balance, err := getAssetInfo(ctx, cli, priv.PublicKey(), outAssetID, true)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		// Select out tick
+		outTick, err := promptAmount("out tick", outAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select supply
+		supply, err := promptAmount(
+			"supply (must be multiple of out tick)",
+			outAssetID,
+			balance,
+			func(input uint64) error {
+				if input%outTick != 0 {
+					return ErrNotMultiple
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// Select expiry
+		expiry, err := promptExpiry()
+		if err != nil {
+			return err
+		}
+
+		// Show trading fees
+		treasury, err := getTreasury(ctx, cli)
+		if err != nil {
+			return err
+		}
+
+		// Cross the other side of the book
+		side := actions.OrderSide(inAssetID, outAssetID)
+		book, err := cli.Orders(ctx, actions.BookID(side, inAssetID, outAssetID))
+		if err != nil {
+			return err
+		}
+		resting := book.Bids
+		if side == actions.SideBid {
+			resting = book.Asks
+		}
+		if len(resting) > actions.MaxOrderMatches {
+			resting = resting[:actions.MaxOrderMatches]
+		}
+		matches := make([]*actions.OrderMatch, len(resting))
+		for i, order := range resting {
+			owner, err := utils.ParseAddress(order.Producer)
+			if err != nil {
+				return err
+			}
+			matches[i] = &actions.OrderMatch{Order: order.ID, Owner: owner}
+		}
+		hutils.Outf("{{cyan}}resting orders to match:{{/}} %d\n", len(matches))
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		txID, success, output, err := sendAndWait(ctx, cli, &actions.SubmitLimitOrder{
+			Side:     side,
+			In:       inAssetID,
+			InTick:   inTick,
+			Out:      outAssetID,
+			OutTick:  outTick,
+			Supply:   supply,
+			Expiry:   expiry,
+			Matches:  matches,
+			Treasury: treasury,
+		}, factory)
+		if err != nil {
+			return err
+		}
+		if success {
+			return printLimitOrderResult(txID, inAssetID, outAssetID, output)
+		}
+		return nil
+	},
+}
+
+var fillOrderCmd = &cobra.Command{
+	Use: "fill-order",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select inbound asset
+		inAssetID, err := promptAsset(ctx, cli, "in assetID", true)
+		if err != nil {
+			return err
+		}
+		balance, err := getAssetInfo(ctx, cli, priv.PublicKey(), inAssetID, true)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+		if _, err := getAssetInfo(ctx, cli, priv.PublicKey(), outAssetID, false); err != nil {
+			return err
+		}
+
+		// View orders (asks selling [outAssetID] and bids buying it with
+		// [inAssetID] can both be filled by paying [inAssetID])
+		asks, err := cli.Orders(ctx, actions.BookID(actions.SideAsk, inAssetID, outAssetID))
+		if err != nil {
+			return err
+		}
+		bids, err := cli.Orders(ctx, actions.BookID(actions.SideBid, inAssetID, outAssetID))
+		if err != nil {
+			return err
+		}
+		orders := append(asks.Asks, bids.Bids...)
+		if len(orders) == 0 {
+			hutils.Outf("{{red}}no available orders{{/}}\n")
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+		hutils.Outf("{{cyan}}available orders:{{/}} %d\n", len(orders))
+		max := 20
+		if len(orders) < max {
+			max = len(orders)
+		}
+		for i := 0; i < max; i++ {
+			order := orders[i]
+			hutils.Outf(
+				"%d) {{cyan}}Rate(in/out):{{/}} %.4f {{cyan}}InTick:{{/}} %s %s {{cyan}}OutTick:{{/}} %s %s {{cyan}}Remaining:{{/}} %s %s\n",
+				i,
+				float64(order.EnergyAmount)/float64(order.TokensPaid),
+				valueString(inAssetID, order.EnergyAmount),
+				assetString(inAssetID),
+				valueString(outAssetID, order.TokensPaid),
+				assetString(outAssetID),
+				valueString(outAssetID, order.Remaining),
+				assetString(outAssetID),
+			)
+		}
+
+		// Select order
+		orderIndex, err := promptChoice("select order", max)
+		if err != nil {
+			return err
+		}
+		order := orders[orderIndex]
+
+		// Select input to trade
+		value, err := promptAmount(
+			"value (must be multiple of in tick)",
+			inAssetID,
+			balance,
+			func(input uint64) error {
+				if input%order.EnergyAmount != 0 {
+					return ErrNotMultiple
+				}
+				multiples := input / order.EnergyAmount
+				requiredRemainder := order.TokensPaid * multiples
+				if requiredRemainder > order.Remaining {
+					return ErrInsufficientSupply
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// Show trading fees
+		treasury, err := getTreasury(ctx, cli)
+		if err != nil {
+			return err
+		}
+
+		// Select taker restrictions
+		fillOrKill, err := promptBool("fill or kill")
+		if err != nil {
+			return err
+		}
+		minReceived, err := promptAmount("min received (0 for none)", outAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		owner, err := utils.ParseAddress(order.Producer)
+		if err != nil {
+			return err
+		}
+		_, success, output, err := sendAndWait(ctx, cli, &actions.FillEnergyOrder{
+			Order:       order.ID,
+			Owner:       owner,
+			In:          inAssetID,
+			Out:         outAssetID,
+			Value:       value,
+			FillOrKill:  fillOrKill,
+			MinReceived: minReceived,
+			Treasury:    treasury,
+		}, factory)
+		if err != nil {
+			return err
+		}
+		if success {
+			return printOrderResult(inAssetID, outAssetID, output)
+		}
+		return nil
+	},
+}
+
+var fillBestOrdersCmd = &cobra.Command{
+	Use: "fill-best-orders",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select inbound asset
+		inAssetID, err := promptAsset(ctx, cli, "in assetID", true)
+		if err != nil {
+			return err
+		}
+		balance, err := getAssetInfo(ctx, cli, priv.PublicKey(), inAssetID, true)
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+		if _, err := getAssetInfo(ctx, cli, priv.PublicKey(), outAssetID, false); err != nil {
+			return err
+		}
+
+		// Select quantity to buy
+		quantity, err := promptAmount("quantity", outAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select max input to spend
+		maxIn, err := promptAmount("max in", inAssetID, balance, nil)
+		if err != nil {
+			return err
+		}
+
+		// Show trading fees
+		treasury, err := getTreasury(ctx, cli)
+		if err != nil {
+			return err
+		}
+
+		// Collect the best orders that can be filled with [inAssetID]
+		asks, err := cli.Orders(ctx, actions.BookID(actions.SideAsk, inAssetID, outAssetID))
+		if err != nil {
+			return err
+		}
+		bids, err := cli.Orders(ctx, actions.BookID(actions.SideBid, inAssetID, outAssetID))
+		if err != nil {
+			return err
+		}
+		orders := append(asks.Asks, bids.Bids...)
+		if len(orders) == 0 {
+			hutils.Outf("{{red}}no available orders{{/}}\n")
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+		sort.SliceStable(orders, func(i, j int) bool {
+			return float64(orders[i].TokensPaid)/float64(orders[i].EnergyAmount) >
+				float64(orders[j].TokensPaid)/float64(orders[j].EnergyAmount)
+		})
+		if len(orders) > actions.MaxOrderMatches {
+			orders = orders[:actions.MaxOrderMatches]
+		}
+		candidates := make([]*actions.OrderMatch, len(orders))
+		for i, order := range orders {
+			owner, err := utils.ParseAddress(order.Producer)
+			if err != nil {
+				return err
+			}
+			candidates[i] = &actions.OrderMatch{Order: order.ID, Owner: owner}
+		}
+		hutils.Outf("{{cyan}}candidate orders:{{/}} %d\n", len(candidates))
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, success, output, err := sendAndWait(ctx, cli, &actions.FillBestEnergyOrders{
+			In:       inAssetID,
+			Out:      outAssetID,
+			Quantity: quantity,
+			MaxIn:    maxIn,
+			Orders:   candidates,
+			Treasury: treasury,
+		}, factory)
+		if err != nil {
+			return err
+		}
+		if success {
+			return printOrdersResult(inAssetID, outAssetID, output)
+		}
+		return nil
+	},
+}
+
+var closeOrderCmd = &cobra.Command{
+	Use: "close-order",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select order
+		orderID, err := promptID("orderID")
+		if err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.CloseEnergyOrder{
+			Order: orderID,
+			Out:   outAssetID,
+		}, factory)
+		return err
+	},
+}
+
+var amendOrderCmd = &cobra.Command{
+	Use: "amend-order",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select order
+		orderID, err := promptID("orderID")
+		if err != nil {
+			return err
+		}
+
+		// Select inbound asset
+		inAssetID, err := promptAsset(ctx, cli, "in assetID", true)
+		if err != nil {
+			return err
+		}
+
+		// Select new in tick
+		inTick, err := promptAmount("in tick", inAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+
+		// Select new out tick
+		outTick, err := promptAmount("out tick", outAssetID, consts.MaxUint64, nil)
+		if err != nil {
+			return err
+		}
+
+		// Select new supply
+		supply, err := promptAmount(
+			"supply (must be multiple of out tick)",
+			outAssetID,
+			consts.MaxUint64,
+			func(input uint64) error {
+				if input%outTick != 0 {
+					return ErrNotMultiple
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.AmendEnergyOrder{
+			Order:   orderID,
+			Out:     outAssetID,
+			InTick:  inTick,
+			OutTick: outTick,
+			Supply:  supply,
+		}, factory)
+		return err
+	},
+}
+
+var reclaimOrderCmd = &cobra.Command{
+	Use: "reclaim-order",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select order
+		orderID, err := promptID("orderID")
+		if err != nil {
+			return err
+		}
+
+		// Select owner
+		owner, err := promptAddress("owner")
+		if err != nil {
+			return err
+		}
+
+		// Select outbound asset
+		outAssetID, err := promptAsset(ctx, cli, "out assetID", true)
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.ReclaimExpiredOrder{
+			Order: orderID,
+			Owner: owner,
+			Out:   outAssetID,
+		}, factory)
+		return err
+	},
+}
+
+var transferCertificateCmd = &cobra.Command{
+	Use: "transfer-certificate",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select certificate
+		certificateID, err := promptID("certificateID")
+		if err != nil {
+			return err
+		}
+		owned, err := getCertificate(ctx, cli, priv.PublicKey(), certificateID)
+		if !owned || err != nil {
+			return err
+		}
+
+		// Select recipient
+		recipient, err := promptAddress("recipient")
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.TransferCertificate{
+			Certificate: certificateID,
+			To:          recipient,
+		}, factory)
+		return err
+	},
+}
+
+var retireCertificateCmd = &cobra.Command{
+	Use: "retire-certificate",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select certificate
+		certificateID, err := promptID("certificateID")
+		if err != nil {
+			return err
+		}
+		owned, err := getCertificate(ctx, cli, priv.PublicKey(), certificateID)
+		if !owned || err != nil {
+			return err
+		}
+
+		// Name beneficiary
+		promptText := promptui.Prompt{
+			Label: "beneficiary",
+			Validate: func(input string) error {
+				if len(input) == 0 {
+					return ErrInputEmpty
+				}
+				if len(input) > actions.MaxCertificateFieldSize {
+					return ErrInputTooLarge
+				}
+				return nil
+			},
+		}
+		beneficiary, err := promptText.Run()
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.RetireCertificate{
+			Certificate: certificateID,
+			Beneficiary: []byte(beneficiary),
+		}, factory)
+		return err
+	},
+}
+
+var grantRoleCmd = &cobra.Command{
+	Use: "grant-role",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select account
+		account, err := promptAddress("account")
+		if err != nil {
+			return err
+		}
+		roles, err := getRoles(ctx, cli, account)
+		if err != nil {
+			return err
+		}
+
+		// Select role
+		role, err := promptRole("role to grant")
+		if err != nil {
+			return err
+		}
+		if roles&role != 0 {
+			hutils.Outf("{{red}}account already has %s role{{/}}\n", rolesString(role))
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.GrantRole{
+			To:   account,
+			Role: role,
+		}, factory)
+		return err
+	},
+}
+
+var revokeRoleCmd = &cobra.Command{
+	Use: "revoke-role",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select account
+		account, err := promptAddress("account")
+		if err != nil {
+			return err
+		}
+		roles, err := getRoles(ctx, cli, account)
+		if err != nil {
+			return err
+		}
+
+		// Select role
+		role, err := promptRole("role to revoke")
+		if err != nil {
+			return err
+		}
+		if roles&role == 0 {
+			hutils.Outf("{{red}}account does not have %s role{{/}}\n", rolesString(role))
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.RevokeRole{
+			From: account,
+			Role: role,
+		}, factory)
+		return err
+	},
+}
+
+var updateAssetOwnerCmd = &cobra.Command{
+	Use: "update-asset-owner",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		owned, _, err := getOwnedAsset(ctx, cli, priv.PublicKey(), assetID)
+		if !owned || err != nil {
+			return err
+		}
+
+		// Select new owner
+		owner, err := promptAddress("new owner")
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.UpdateAssetOwner{
+			Asset: assetID,
+			Owner: owner,
+		}, factory)
+		return err
+	},
+}
+
+var updateAssetMetadataCmd = &cobra.Command{
+	Use: "update-asset-metadata",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		owned, _, err := getOwnedAsset(ctx, cli, priv.PublicKey(), assetID)
+		if !owned || err != nil {
+			return err
+		}
+
+		// Describe asset
+		metadata, err := promptAssetMetadata()
+		if err != nil {
+			return err
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.UpdateAssetMetadata{
+			Asset:    assetID,
+			Metadata: metadata,
+		}, factory)
+		return err
+	},
+}
+
+var freezeAssetCmd = &cobra.Command{
+	Use: "freeze-asset",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		owned, frozen, err := getOwnedAsset(ctx, cli, priv.PublicKey(), assetID)
+		if !owned || err != nil {
+			return err
+		}
+		if frozen {
+			hutils.Outf("{{red}}%s is already frozen{{/}}\n", assetID)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.FreezeAsset{Asset: assetID}, factory)
+		return err
+	},
+}
+
+var unfreezeAssetCmd = &cobra.Command{
+	Use: "unfreeze-asset",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		owned, frozen, err := getOwnedAsset(ctx, cli, priv.PublicKey(), assetID)
+		if !owned || err != nil {
+			return err
+		}
+		if !frozen {
+			hutils.Outf("{{red}}%s is not frozen{{/}}\n", assetID)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.UnfreezeAsset{Asset: assetID}, factory)
+		return err
+	},
+}
+
+var registerSymbolCmd = &cobra.Command{
+	Use: "register-symbol",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		priv, factory, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+
+		// Select asset
+		assetID, err := promptAsset(ctx, cli, "assetID", false)
+		if err != nil {
+			return err
+		}
+		owned, _, err := getOwnedAsset(ctx, cli, priv.PublicKey(), assetID)
+		if !owned || err != nil {
+			return err
+		}
+		exists, symbol, err := cli.AssetSymbol(ctx, assetID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			hutils.Outf("{{red}}%s is already registered as %s{{/}}\n", assetID, symbol)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+
+		// Select symbol
+		symbol, err = promptSymbol("symbol")
+		if err != nil {
+			return err
+		}
+		exists, registered, err := cli.Symbol(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if exists {
+			hutils.Outf("{{red}}%s is already registered to %s{{/}}\n", symbol, registered)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return nil
+		}
+
+		// Confirm action
+		cont, err := promptContinue()
+		if !cont || err != nil {
+			return err
+		}
+
+		// Generate transaction
+		_, _, _, err = sendAndWait(ctx, cli, &actions.RegisterSymbol{
+			Asset:  assetID,
+			Symbol: symbol,
+		}, factory)
+		return err
+	},
+}
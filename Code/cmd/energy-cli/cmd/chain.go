@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/spf13/cobra"
+)
+
+var chainCmd = &cobra.Command{
+	Use: "chain",
+	RunE: func(*cobra.Command, []string) error {
+		return ErrMissingSubcommand
+	},
+}
+
+var importChainCmd = &cobra.Command{
+	Use: "import",
+	RunE: func(*cobra.Command, []string) error {
+		chainID, err := promptID("chainID")
+		if err != nil {
+			return err
+		}
+		uri, err := promptString("uri")
+		if err != nil {
+			return err
+		}
+		if err := StoreChain(chainID, uri); err != nil {
+			return err
+		}
+		utils.Outf(
+			"{{yellow}}stored chainID:{{/}} %s {{yellow}}uri:{{/}} %s\n",
+			chainID,
+			uri,
+		)
+		return StoreDefault(defaultChainKey, chainID[:])
+	},
+}
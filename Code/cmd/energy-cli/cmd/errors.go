@@ -0,0 +1,18 @@
+package cmd
+
+import "errors"
+
+var (
+	ErrInputEmpty          = errors.New("input is empty")
+	ErrInputTooLarge       = errors.New("input is too large")
+	ErrInvalidArgs         = errors.New("invalid args")
+	ErrMissingSubcommand   = errors.New("must specify a subcommand")
+	ErrIndexOutOfRange     = errors.New("index out-of-range")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInvalidChoice       = errors.New("invalid choice")
+	ErrNotMultiple         = errors.New("must be a multiple")
+	ErrInsufficientSupply  = errors.New("insufficient supply")
+	ErrDuplicate           = errors.New("duplicate")
+	ErrNoKeys              = errors.New("no available keys")
+	ErrNoChains            = errors.New("no available chains")
+)
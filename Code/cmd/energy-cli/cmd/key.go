@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/crypto"
+	hutils "github.com/ava-labs/hypersdk/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/consts"
+	"github.com/bbehrman10/energyavavm/rpc"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+var keyCmd = &cobra.Command{
+	Use: "key",
+	RunE: func(*cobra.Command, []string) error {
+		return ErrMissingSubcommand
+	},
+}
+
+var genKeyCmd = &cobra.Command{
+	Use: "generate",
+	RunE: func(*cobra.Command, []string) error {
+		// TODO: encrypt key
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			return err
+		}
+		if err := StoreKey(priv); err != nil {
+			return err
+		}
+		publicKey := priv.PublicKey()
+		if err := StoreDefault(defaultKeyKey, publicKey[:]); err != nil {
+			return err
+		}
+		color.Green(
+			"created address %s",
+			utils.Address(publicKey),
+		)
+		return nil
+	},
+}
+
+var importKeyCmd = &cobra.Command{
+	Use: "import [path]",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ErrInvalidArgs
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		priv, err := crypto.LoadKey(args[0])
+		if err != nil {
+			return err
+		}
+		if err := StoreKey(priv); err != nil {
+			return err
+		}
+		publicKey := priv.PublicKey()
+		if err := StoreDefault(defaultKeyKey, publicKey[:]); err != nil {
+			return err
+		}
+		color.Green(
+			"imported address %s",
+			utils.Address(publicKey),
+		)
+		return nil
+	},
+}
+
+var setKeyCmd = &cobra.Command{
+	Use: "set",
+	RunE: func(*cobra.Command, []string) error {
+		keys, err := GetKeys()
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			hutils.Outf("{{red}}no stored keys{{/}}\n")
+			return nil
+		}
+		chainID, uris, err := GetDefaultChain()
+		if err != nil {
+			return err
+		}
+		if len(uris) == 0 {
+			hutils.Outf("{{red}}no available chains{{/}}\n")
+			return nil
+		}
+		cli := rpc.NewJSONRPCClient(uris[0], chainID)
+		hutils.Outf("{{cyan}}stored keys:{{/}} %d\n", len(keys))
+		for i := 0; i < len(keys); i++ {
+			address := utils.Address(keys[i].PublicKey())
+			balance, err := cli.Balance(context.TODO(), address, ids.Empty)
+			if err != nil {
+				return err
+			}
+			hutils.Outf(
+				"%d) {{cyan}}address:{{/}} %s {{cyan}}balance:{{/}} %s %s\n",
+				i,
+				address,
+				valueString(ids.Empty, balance),
+				consts.Symbol,
+			)
+		}
+
+		// Select key
+		keyIndex, err := promptChoice("set default key", len(keys))
+		if err != nil {
+			return err
+		}
+		key := keys[keyIndex]
+		publicKey := key.PublicKey()
+		return StoreDefault(defaultKeyKey, publicKey[:])
+	},
+}
+
+var ordersKeyCmd = &cobra.Command{
+	Use: "orders [address]",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return ErrInvalidArgs
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+		priv, _, cli, err := defaultActor()
+		if err != nil {
+			return err
+		}
+		address := utils.Address(priv.PublicKey())
+		if len(args) == 1 {
+			if _, err := utils.ParseAddress(args[0]); err != nil {
+				return err
+			}
+			address = args[0]
+		}
+
+		// List orders in every pair, tracked or not
+		count := 0
+		cursor := ids.Empty
+		for {
+			orders, next, err := cli.OrdersByOwner(ctx, address, cursor, 0)
+			if err != nil {
+				return err
+			}
+			for _, order := range orders {
+				side := "ask"
+				if order.Side == actions.SideBid {
+					side = "bid"
+				}
+				hutils.Outf(
+					"{{cyan}}orderID:{{/}} %s {{cyan}}side:{{/}} %s {{cyan}}in:{{/}} %s %s {{cyan}}out:{{/}} %s %s {{cyan}}remaining:{{/}} %s %s\n",
+					order.ID,
+					side,
+					valueString(order.In, order.InTick),
+					assetString(order.In),
+					valueString(order.Out, order.OutTick),
+					assetString(order.Out),
+					valueString(order.Out, order.Remaining),
+					assetString(order.Out),
+				)
+			}
+			count += len(orders)
+			if next == ids.Empty {
+				break
+			}
+			cursor = next
+		}
+		hutils.Outf("{{cyan}}open orders:{{/}} %d\n", count)
+		return nil
+	},
+}
@@ -0,0 +1,92 @@
+// "energy-cli" implements energyvm client operation interface.
+package cmd
+
+import (
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/hypersdk/pebble"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/spf13/cobra"
+)
+
+const defaultDatabase = ".energy-cli"
+
+var (
+	dbPath string
+	db     database.Database
+
+	rootCmd = &cobra.Command{
+		Use:        "energy-cli",
+		Short:      "EnergyVM CLI",
+		SuggestFor: []string{"energy-cli", "energycli"},
+	}
+)
+
+func init() {
+	cobra.EnablePrefixMatching = true
+	rootCmd.AddCommand(
+		keyCmd,
+		chainCmd,
+		actionCmd,
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&dbPath,
+		"database",
+		defaultDatabase,
+		"path to database (will create it missing)",
+	)
+	rootCmd.PersistentPreRunE = func(*cobra.Command, []string) error {
+		utils.Outf("{{yellow}}database:{{/}} %s\n", dbPath)
+		var err error
+		db, err = pebble.New(dbPath, pebble.NewDefaultConfig())
+		return err
+	}
+	rootCmd.PersistentPostRunE = func(*cobra.Command, []string) error {
+		return CloseDatabase()
+	}
+	rootCmd.SilenceErrors = true
+
+	// key
+	keyCmd.AddCommand(
+		genKeyCmd,
+		importKeyCmd,
+		setKeyCmd,
+		ordersKeyCmd,
+	)
+
+	// chain
+	chainCmd.AddCommand(
+		importChainCmd,
+	)
+
+	// actions
+	actionCmd.AddCommand(
+		initializeAssetCmd,
+		produceCmd,
+		consumeCmd,
+		transferCmd,
+
+		createOrderCmd,
+		limitOrderCmd,
+		fillOrderCmd,
+		fillBestOrdersCmd,
+		closeOrderCmd,
+		amendOrderCmd,
+		reclaimOrderCmd,
+
+		transferCertificateCmd,
+		retireCertificateCmd,
+
+		grantRoleCmd,
+		revokeRoleCmd,
+
+		updateAssetOwnerCmd,
+		updateAssetMetadataCmd,
+		freezeAssetCmd,
+		unfreezeAssetCmd,
+		registerSymbolCmd,
+	)
+}
+
+func Execute() error {
+	return rootCmd.Execute()
+}
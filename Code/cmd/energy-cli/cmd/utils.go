@@ -0,0 +1,733 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/crypto"
+	hutils "github.com/ava-labs/hypersdk/utils"
+	"github.com/manifoldco/promptui"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/consts"
+	"github.com/bbehrman10/energyavavm/rpc"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+func promptAddress(label string) (crypto.PublicKey, error) {
+	promptText := promptui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			_, err := utils.ParseAddress(input)
+			return err
+		},
+	}
+	recipient, err := promptText.Run()
+	if err != nil {
+		return crypto.EmptyPublicKey, err
+	}
+	recipient = strings.TrimSpace(recipient)
+	return utils.ParseAddress(recipient)
+}
+
+func promptString(label string) (string, error) {
+	promptText := promptui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			return nil
+		},
+	}
+	text, err := promptText.Run()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), err
+}
+
+// promptSymbol returns an uppercase symbol that isn't the native symbol.
+func promptSymbol(label string) (string, error) {
+	promptText := promptui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			symbol := strings.ToUpper(strings.TrimSpace(input))
+			if symbol == consts.Symbol {
+				return ErrInvalidChoice
+			}
+			return actions.VerifySymbol(symbol)
+		},
+	}
+	text, err := promptText.Run()
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(strings.TrimSpace(text)), err
+}
+
+// promptAsset accepts either an asset ID or a registered symbol, which is
+// resolved with [cli].
+func promptAsset(
+	ctx context.Context,
+	cli *rpc.JSONRPCClient,
+	label string,
+	allowNative bool,
+) (ids.ID, error) {
+	text := fmt.Sprintf("%s or symbol (use %s for native token)", label, consts.Symbol)
+	if !allowNative {
+		text = fmt.Sprintf("%s or symbol", label)
+	}
+	promptText := promptui.Prompt{
+		Label: text,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			if input == consts.Symbol {
+				if allowNative {
+					return nil
+				}
+				return ErrInvalidChoice
+			}
+			if _, err := ids.FromString(input); err == nil {
+				return nil
+			}
+			return actions.VerifySymbol(input)
+		},
+	}
+	asset, err := promptText.Run()
+	if err != nil {
+		return ids.Empty, err
+	}
+	assetID, err := actions.ParseAsset(strings.TrimSpace(asset), func(symbol string) (ids.ID, bool, error) {
+		exists, assetID, err := cli.Symbol(ctx, symbol)
+		return assetID, exists, err
+	})
+	if err != nil {
+		return ids.Empty, err
+	}
+	if !allowNative && assetID == ids.Empty {
+		return ids.Empty, ErrInvalidChoice
+	}
+	return assetID, nil
+}
+
+func promptAmount(
+	label string,
+	assetID ids.ID,
+	balance uint64,
+	f func(input uint64) error,
+) (uint64, error) {
+	promptText := promptui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			amount, err := parseAmount(assetID, input)
+			if err != nil {
+				return err
+			}
+			if amount > balance {
+				return ErrInsufficientBalance
+			}
+			if f != nil {
+				return f(amount)
+			}
+			return nil
+		},
+	}
+	rawAmount, err := promptText.Run()
+	if err != nil {
+		return 0, err
+	}
+	return parseAmount(assetID, strings.TrimSpace(rawAmount))
+}
+
+func parseAmount(assetID ids.ID, input string) (uint64, error) {
+	if assetID == ids.Empty {
+		return hutils.ParseBalance(input)
+	}
+	// Energy assets are denoted in raw kWh
+	return strconv.ParseUint(input, 10, 64)
+}
+
+func promptChoice(label string, max int) (int, error) {
+	promptText := promptui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			index, err := strconv.Atoi(input)
+			if err != nil {
+				return err
+			}
+			if index >= max || index < 0 {
+				return ErrIndexOutOfRange
+			}
+			return nil
+		},
+	}
+	rawIndex, err := promptText.Run()
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(rawIndex)
+}
+
+// promptAssetMetadata asks for each field of an [actions.AssetMetadata] and
+// returns its encoding.
+func promptAssetMetadata() ([]byte, error) {
+	var m actions.AssetMetadata
+	symbol, err := promptString("symbol")
+	if err != nil {
+		return nil, err
+	}
+	m.Symbol = strings.ToUpper(symbol)
+	unit, err := promptChoice("unit (0: Wh, 1: kWh, 2: MWh)", int(actions.UnitMWh)+1)
+	if err != nil {
+		return nil, err
+	}
+	m.Unit = actions.EnergyUnit(unit)
+	decimals, err := promptChoice("decimals", actions.MaxAssetDecimals+1)
+	if err != nil {
+		return nil, err
+	}
+	m.Decimals = uint8(decimals)
+	m.Source, err = promptString("energy source")
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []struct {
+		label string
+		dest  *string
+	}{
+		{"grid region (optional)", &m.Region},
+		{"description (optional)", &m.Description},
+	} {
+		promptText := promptui.Prompt{Label: field.label}
+		*field.dest, err = promptText.Run()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m.Marshal()
+}
+
+// roleNames are the names of the roles defined in storage, in bit order.
+var roleNames = []string{"operator", "producer", "retailer", "consumer"}
+
+func promptRole(label string) (uint8, error) {
+	choices := make([]string, len(roleNames))
+	for i, name := range roleNames {
+		choices[i] = fmt.Sprintf("%d: %s", i, name)
+	}
+	index, err := promptChoice(fmt.Sprintf("%s (%s)", label, strings.Join(choices, ", ")), len(roleNames))
+	if err != nil {
+		return 0, err
+	}
+	return 1 << index, nil
+}
+
+func rolesString(roles uint8) string {
+	names := []string{}
+	for i, name := range roleNames {
+		if roles&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// promptExpiry asks for an optional lifetime in seconds and returns the
+// resulting unix timestamp (0 if the input is left empty).
+func promptExpiry() (int64, error) {
+	promptText := promptui.Prompt{
+		Label: "expires in seconds (optional)",
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return nil
+			}
+			seconds, err := strconv.ParseInt(input, 10, 64)
+			if err != nil {
+				return err
+			}
+			if seconds <= 0 {
+				return ErrInvalidChoice
+			}
+			return nil
+		},
+	}
+	rawSeconds, err := promptText.Run()
+	if err != nil {
+		return 0, err
+	}
+	rawSeconds = strings.TrimSpace(rawSeconds)
+	if len(rawSeconds) == 0 {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseInt(rawSeconds, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Now().Unix() + seconds, nil
+}
+
+func promptBool(label string) (bool, error) {
+	promptText := promptui.Prompt{
+		Label: fmt.Sprintf("%s (y/n)", label),
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			lower := strings.ToLower(input)
+			if lower == "y" || lower == "n" {
+				return nil
+			}
+			return ErrInvalidChoice
+		},
+	}
+	rawBool, err := promptText.Run()
+	if err != nil {
+		return false, err
+	}
+	return strings.ToLower(rawBool) == "y", nil
+}
+
+func promptContinue() (bool, error) {
+	promptText := promptui.Prompt{
+		Label: "continue (y/n)",
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			lower := strings.ToLower(input)
+			if lower == "y" || lower == "n" {
+				return nil
+			}
+			return ErrInvalidChoice
+		},
+	}
+	rawContinue, err := promptText.Run()
+	if err != nil {
+		return false, err
+	}
+	cont := strings.ToLower(rawContinue)
+	if cont == "n" {
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, nil
+	}
+	return true, nil
+}
+
+func promptID(label string) (ids.ID, error) {
+	promptText := promptui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if len(input) == 0 {
+				return ErrInputEmpty
+			}
+			_, err := ids.FromString(input)
+			return err
+		},
+	}
+	rawID, err := promptText.Run()
+	if err != nil {
+		return ids.Empty, err
+	}
+	rawID = strings.TrimSpace(rawID)
+	id, err := ids.FromString(rawID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	return id, nil
+}
+
+func valueString(assetID ids.ID, value uint64) string {
+	if assetID == ids.Empty {
+		return hutils.FormatBalance(value)
+	}
+	// Energy assets are denoted in raw kWh
+	return strconv.FormatUint(value, 10)
+}
+
+func assetString(assetID ids.ID) string {
+	if assetID == ids.Empty {
+		return consts.Symbol
+	}
+	return assetID.String()
+}
+
+// metadataString describes [metadata] if it is an [actions.AssetMetadata] and
+// returns it unchanged otherwise.
+func metadataString(metadata []byte) string {
+	m, err := actions.UnmarshalAssetMetadata(metadata)
+	if err != nil {
+		return string(metadata)
+	}
+	s := fmt.Sprintf("%s (%s, 10^-%d %s", m.Symbol, m.Source, m.Decimals, m.Unit)
+	if len(m.Region) > 0 {
+		s += ", " + m.Region
+	}
+	s += ")"
+	if len(m.Description) > 0 {
+		s += " " + m.Description
+	}
+	return s
+}
+
+func printStatus(txID ids.ID, success bool, output []byte) {
+	status := "⚠️"
+	if success {
+		status = "✅"
+	}
+	hutils.Outf("%s {{yellow}}txID:{{/}} %s\n", status, txID)
+	if !success && len(output) > 0 {
+		hutils.Outf("{{red}}output:{{/}} %s\n", string(output))
+	}
+}
+
+func printOrderResult(in ids.ID, out ids.ID, output []byte) error {
+	result, err := actions.UnmarshalOrderResult(output)
+	if err != nil {
+		return err
+	}
+	hutils.Outf(
+		"{{yellow}}in:{{/}} %s %s {{yellow}}out:{{/}} %s %s {{yellow}}remaining:{{/}} %s %s\n",
+		valueString(in, result.In),
+		assetString(in),
+		valueString(out, result.Out),
+		assetString(out),
+		valueString(out, result.Remaining),
+		assetString(out),
+	)
+	if result.MakerFee > 0 || result.TakerFee > 0 {
+		hutils.Outf(
+			"{{yellow}}maker fee:{{/}} %s %s {{yellow}}taker fee:{{/}} %s %s\n",
+			valueString(in, result.MakerFee),
+			assetString(in),
+			valueString(out, result.TakerFee),
+			assetString(out),
+		)
+	}
+	return nil
+}
+
+func printLimitOrderResult(txID ids.ID, in ids.ID, out ids.ID, output []byte) error {
+	result, err := actions.UnmarshalLimitOrderResult(output)
+	if err != nil {
+		return err
+	}
+	for _, fill := range result.Fills {
+		hutils.Outf(
+			"{{yellow}}filled:{{/}} %s {{yellow}}paid:{{/}} %s %s {{yellow}}received:{{/}} %s %s\n",
+			fill.Order,
+			valueString(out, fill.In),
+			assetString(out),
+			valueString(in, fill.Out),
+			assetString(in),
+		)
+		if fill.MakerFee > 0 || fill.TakerFee > 0 {
+			hutils.Outf(
+				"{{yellow}}maker fee:{{/}} %s %s {{yellow}}taker fee:{{/}} %s %s\n",
+				valueString(out, fill.MakerFee),
+				assetString(out),
+				valueString(in, fill.TakerFee),
+				assetString(in),
+			)
+		}
+	}
+	if result.Listed > 0 {
+		hutils.Outf(
+			"{{yellow}}orderID:{{/}} %s {{yellow}}listed:{{/}} %s %s\n",
+			txID,
+			valueString(out, result.Listed),
+			assetString(out),
+		)
+	}
+	return nil
+}
+
+func printOrdersResult(in ids.ID, out ids.ID, output []byte) error {
+	result, err := actions.UnmarshalOrdersResult(output)
+	if err != nil {
+		return err
+	}
+	for _, fill := range result.Fills {
+		hutils.Outf(
+			"{{yellow}}filled:{{/}} %s {{yellow}}in:{{/}} %s %s {{yellow}}out:{{/}} %s %s\n",
+			fill.Order,
+			valueString(in, fill.In),
+			assetString(in),
+			valueString(out, fill.Out),
+			assetString(out),
+		)
+		if fill.MakerFee > 0 || fill.TakerFee > 0 {
+			hutils.Outf(
+				"{{yellow}}maker fee:{{/}} %s %s {{yellow}}taker fee:{{/}} %s %s\n",
+				valueString(in, fill.MakerFee),
+				assetString(in),
+				valueString(out, fill.TakerFee),
+				assetString(out),
+			)
+		}
+	}
+	hutils.Outf(
+		"{{yellow}}total in:{{/}} %s %s {{yellow}}total out:{{/}} %s %s\n",
+		valueString(in, result.In),
+		assetString(in),
+		valueString(out, result.Out),
+		assetString(out),
+	)
+	return nil
+}
+
+// sendAndWait signs [action], submits it and waits for it to be accepted.
+func sendAndWait(
+	ctx context.Context,
+	cli *rpc.JSONRPCClient,
+	action chain.Action,
+	factory *auth.ED25519Factory,
+) (ids.ID, bool, []byte, error) {
+	submit, tx, _, err := cli.GenerateTransaction(ctx, action, factory)
+	if err != nil {
+		return ids.Empty, false, nil, err
+	}
+	if err := submit(ctx); err != nil {
+		return ids.Empty, false, nil, err
+	}
+	success, output, err := cli.WaitForTransaction(ctx, tx.ID())
+	if err != nil {
+		return ids.Empty, false, nil, err
+	}
+	printStatus(tx.ID(), success, output)
+	return tx.ID(), success, output, nil
+}
+
+func getAssetInfo(
+	ctx context.Context,
+	cli *rpc.JSONRPCClient,
+	publicKey crypto.PublicKey,
+	assetID ids.ID,
+	checkBalance bool,
+) (uint64, error) {
+	if assetID != ids.Empty {
+		exists, metadata, supply, owner, warp, frozen, err := cli.Asset(ctx, assetID)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			hutils.Outf("{{red}}%s does not exist{{/}}\n", assetID)
+			hutils.Outf("{{red}}exiting...{{/}}\n")
+			return 0, nil
+		}
+		hutils.Outf(
+			"{{yellow}}metadata:{{/}} %s {{yellow}}supply:{{/}} %d {{yellow}}owner:{{/}} %s {{yellow}}warp:{{/}} %t {{yellow}}frozen:{{/}} %t\n",
+			metadataString(metadata),
+			supply,
+			owner,
+			warp,
+			frozen,
+		)
+	}
+	if !checkBalance {
+		return 0, nil
+	}
+	addr := utils.Address(publicKey)
+	balance, err := cli.Balance(ctx, addr, assetID)
+	if err != nil {
+		return 0, err
+	}
+	if balance == 0 {
+		hutils.Outf("{{red}}balance:{{/}} 0 %s\n", assetString(assetID))
+		hutils.Outf("{{red}}please send funds to %s{{/}}\n", addr)
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return 0, nil
+	}
+	hutils.Outf(
+		"{{yellow}}balance:{{/}} %s %s\n",
+		valueString(assetID, balance),
+		assetString(assetID),
+	)
+	return balance, nil
+}
+
+// getOwnedAsset prints [assetID] and returns whether [actor] can administer
+// it and whether it is frozen.
+func getOwnedAsset(
+	ctx context.Context,
+	cli *rpc.JSONRPCClient,
+	actor crypto.PublicKey,
+	assetID ids.ID,
+) (bool, bool, error) {
+	exists, metadata, supply, owner, warp, frozen, err := cli.Asset(ctx, assetID)
+	if err != nil {
+		return false, false, err
+	}
+	if !exists {
+		hutils.Outf("{{red}}%s does not exist{{/}}\n", assetID)
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, false, nil
+	}
+	if warp {
+		hutils.Outf("{{red}}cannot administer a warped asset{{/}}\n")
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, false, nil
+	}
+	if owner != utils.Address(actor) {
+		hutils.Outf("{{red}}%s is the owner of %s, you are not{{/}}\n", owner, assetID)
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, false, nil
+	}
+	hutils.Outf(
+		"{{yellow}}metadata:{{/}} %s {{yellow}}supply:{{/}} %d {{yellow}}frozen:{{/}} %t\n",
+		metadataString(metadata),
+		supply,
+		frozen,
+	)
+	return true, frozen, nil
+}
+
+// getCertificate prints [certificateID] and returns whether [actor] can
+// transfer or retire it.
+func getCertificate(
+	ctx context.Context,
+	cli *rpc.JSONRPCClient,
+	actor crypto.PublicKey,
+	certificateID ids.ID,
+) (bool, error) {
+	exists, certificate, err := cli.Certificate(ctx, certificateID)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		hutils.Outf("{{red}}%s does not exist{{/}}\n", certificateID)
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, nil
+	}
+	hutils.Outf(
+		"{{yellow}}meter:{{/}} %s {{yellow}}technology:{{/}} %s {{yellow}}region:{{/}} %s {{yellow}}period:{{/}} %s - %s\n",
+		certificate.Meter,
+		string(certificate.Technology),
+		string(certificate.Region),
+		time.Unix(certificate.Start, 0).UTC().Format(time.RFC3339),
+		time.Unix(certificate.End, 0).UTC().Format(time.RFC3339),
+	)
+	if certificate.Retired {
+		hutils.Outf("{{red}}retired for %s{{/}}\n", string(certificate.Beneficiary))
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, nil
+	}
+	if certificate.Owner != utils.Address(actor) {
+		hutils.Outf("{{red}}%s is the owner of %s, you are not{{/}}\n", certificate.Owner, certificateID)
+		hutils.Outf("{{red}}exiting...{{/}}\n")
+		return false, nil
+	}
+	return true, nil
+}
+
+// getRoles prints and returns the roles held by [pk].
+func getRoles(ctx context.Context, cli *rpc.JSONRPCClient, pk crypto.PublicKey) (uint8, error) {
+	roles, err := cli.Roles(ctx, utils.Address(pk))
+	if err != nil {
+		return 0, err
+	}
+	hutils.Outf("{{yellow}}roles:{{/}} %s\n", rolesString(roles))
+	return roles, nil
+}
+
+// getTreasury prints the trading fees of the chain and returns the treasury
+// that collects them.
+func getTreasury(ctx context.Context, cli *rpc.JSONRPCClient) (crypto.PublicKey, error) {
+	g, err := cli.Genesis(ctx)
+	if err != nil {
+		return crypto.EmptyPublicKey, err
+	}
+	rules := g.Rules(time.Now().Unix())
+	if rules.GetMakerFeeBips() > 0 || rules.GetTakerFeeBips() > 0 {
+		hutils.Outf(
+			"{{yellow}}maker fee:{{/}} %d bips {{yellow}}taker fee:{{/}} %d bips {{yellow}}treasury:{{/}} %s\n",
+			rules.GetMakerFeeBips(),
+			rules.GetTakerFeeBips(),
+			g.Treasury,
+		)
+	}
+	return rules.GetTreasury(), nil
+}
+
+func defaultActor() (crypto.PrivateKey, *auth.ED25519Factory, *rpc.JSONRPCClient, error) {
+	priv, err := GetDefaultKey()
+	if err != nil {
+		return crypto.EmptyPrivateKey, nil, nil, err
+	}
+	chainID, uris, err := GetDefaultChain()
+	if err != nil {
+		return crypto.EmptyPrivateKey, nil, nil, err
+	}
+	if len(uris) == 0 {
+		return crypto.EmptyPrivateKey, nil, nil, ErrNoChains
+	}
+	// For [defaultActor], we always send requests to the first returned URI.
+	return priv, auth.NewED25519Factory(priv), rpc.NewJSONRPCClient(uris[0], chainID), nil
+}
+
+func GetDefaultKey() (crypto.PrivateKey, error) {
+	v, err := GetDefault(defaultKeyKey)
+	if err != nil {
+		return crypto.EmptyPrivateKey, err
+	}
+	if len(v) == 0 {
+		return crypto.EmptyPrivateKey, ErrNoKeys
+	}
+	publicKey := crypto.PublicKey(v)
+	priv, err := GetKey(publicKey)
+	if err != nil {
+		return crypto.EmptyPrivateKey, err
+	}
+	hutils.Outf("{{yellow}}address:{{/}} %s\n", utils.Address(publicKey))
+	return priv, nil
+}
+
+func GetDefaultChain() (ids.ID, []string, error) {
+	v, err := GetDefault(defaultChainKey)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	if len(v) == 0 {
+		return ids.Empty, nil, ErrNoChains
+	}
+	chainID := ids.ID(v)
+	uris, err := GetChain(chainID)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	hutils.Outf("{{yellow}}chainID:{{/}} %s\n", chainID)
+	return chainID, uris, nil
+}
+
+func CloseDatabase() error {
+	if db == nil {
+		return nil
+	}
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("unable to close database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+// "energy-cli" implements energyvm client operation interface.
+package main
+
+import (
+	"os"
+
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bbehrman10/energyavavm/cmd/energy-cli/cmd"
+)
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		utils.Outf("{{red}}energy-cli exited with error:{{/}} %+v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
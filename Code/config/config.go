@@ -49,10 +49,10 @@ type Config struct {
 
 	// Order Book
 	//
-	// This is denoted as <asset 1>-<asset 2>
-	//
-	// TODO: add ability to denote min rate/min amount for tracking to avoid spam
-	TrackedPairs []string `json:"trackedPairs"` // which asset ID pairs we care about
+	// Each pair is denoted as <asset 1>-<asset 2>, where each asset is either
+	// an ID or a registered symbol. Pairs naming a symbol that isn't
+	// registered yet are tracked once it is.
+	TrackedPairs []*TrackedPair `json:"trackedPairs"` // which asset pairs we care about
 
 	// Misc
 	TestMode                 bool          `json:"testMode"` // makes gossip/building manual
@@ -86,6 +86,14 @@ func New(nodeID ids.NodeID, b []byte) (*Config, error) {
 		}
 		c.parsedExemptPayers[i] = p[:]
 	}
+	for _, pair := range c.TrackedPairs {
+		if pair == nil {
+			return nil, ErrInvalidTrackedPair
+		}
+		if err := pair.Verify(); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
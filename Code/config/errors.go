@@ -0,0 +1,5 @@
+package config
+
+import "errors"
+
+var ErrInvalidTrackedPair = errors.New("invalid tracked pair")
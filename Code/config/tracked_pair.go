@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TrackedPair is a pair the order book is kept for along with the filters
+// that keep spam out of it. It can also be written as just the pair.
+type TrackedPair struct {
+	// Pair is denoted as <asset 1>-<asset 2>, where each asset is either an
+	// ID or a registered symbol, or is "*" for every pair.
+	Pair string `json:"pair"`
+
+	// MinRemaining drops orders with less than this much energy left.
+	MinRemaining uint64 `json:"minRemaining"`
+
+	// MinPrice and MaxPrice drop orders priced outside of them, in currency
+	// per unit of energy. 0 disables either bound.
+	MinPrice uint64 `json:"minPrice"`
+	MaxPrice uint64 `json:"maxPrice"`
+
+	// MaxOrders drops the orders furthest from the market once more than
+	// this many are listed. 0 disables the limit.
+	MaxOrders int `json:"maxOrders"`
+}
+
+func (t *TrackedPair) UnmarshalJSON(b []byte) error {
+	var pair string
+	if err := json.Unmarshal(b, &pair); err == nil {
+		*t = TrackedPair{Pair: pair}
+		return nil
+	}
+	type trackedPair TrackedPair // drops this method to avoid recursion
+	return json.Unmarshal(b, (*trackedPair)(t))
+}
+
+// Verify returns an error if the filters of [t] can't be applied.
+func (t *TrackedPair) Verify() error {
+	switch {
+	case len(t.Pair) == 0:
+		return fmt.Errorf("%w: pair is empty", ErrInvalidTrackedPair)
+	case t.MaxPrice > 0 && t.MinPrice > t.MaxPrice:
+		return fmt.Errorf("%w: %s min price is above max price", ErrInvalidTrackedPair, t.Pair)
+	case t.MaxOrders < 0:
+		return fmt.Errorf("%w: %s max orders is negative", ErrInvalidTrackedPair, t.Pair)
+	default:
+		return nil
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/hypersdk/builder"
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/gossiper"
@@ -23,8 +24,10 @@ import (
 	"github.com/bbehrman10/energyavavm/consts"
 	"github.com/bbehrman10/energyavavm/energyledger"
 	"github.com/bbehrman10/energyavavm/genesis"
+	_ "github.com/bbehrman10/energyavavm/registry" // ensure registry populated
 	"github.com/bbehrman10/energyavavm/rpc"
 	"github.com/bbehrman10/energyavavm/storage"
+	"github.com/bbehrman10/energyavavm/streaming"
 	"github.com/bbehrman10/energyavavm/version"
 )
 
@@ -43,6 +46,7 @@ type Controller struct {
 	metaDB database.Database
 
 	energyLedger *energyledger.EnergyLedger
+	streamer     *streaming.Server
 }
 
 func New() *vm.VM {
@@ -151,8 +155,19 @@ func (c *Controller) Initialize(
 		gossip = gossiper.NewProposer(inner, gcfg)
 	}
 
-	// Initialize energy ledger used to track all open orders
+	// Initialize energy ledger used to track all open orders and rebuild it
+	// from the orders that were open when the node stopped
+	c.streamer = streaming.New(c, c.config.StreamingBacklogSize)
 	c.energyLedger = energyledger.NewEnergyLedger(c, c.config.TrackedPairs)
+	if err := loadOpenOrders(context.TODO(), c.metaDB, c.energyLedger); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	// Stream accepted blocks to clients. Streaming is optional, so the node
+	// still starts if the port is taken.
+	if err := c.streamer.Listen(c.config.StreamingPort); err != nil {
+		c.inner.Logger().Warn("unable to start streaming server", zap.Error(err))
+	}
 	return c.config, c.genesis, build, gossip, blockDB, stateDB, apis, consts.ActionRegistry, consts.AuthRegistry, nil
 }
 
@@ -166,8 +181,36 @@ func (c *Controller) StateManager() chain.StateManager {
 }
 
 func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) error {
+	c.streamer.Begin(blk)
+	if err := c.accepted(ctx, blk); err != nil {
+		c.streamer.Abort()
+		return err
+	}
+	c.streamer.Publish()
+	return nil
+}
+
+func (c *Controller) accepted(ctx context.Context, blk *chain.StatelessBlock) error {
+	// Blocks are skipped during state sync, so the open orders must be read
+	// from state if the metaDB isn't up to date with the parent of [blk]
+	indexed, height, err := storage.GetOrderIndexHeight(ctx, c.metaDB)
+	if err != nil {
+		return err
+	}
+	if !indexed || blk.Height() > height+1 {
+		state, err := c.inner.State()
+		if err != nil {
+			return err
+		}
+		if err := rebuildOpenOrders(ctx, c.metaDB, state, c.energyLedger, blk.Height()-1); err != nil {
+			return err
+		}
+		c.inner.Logger().Info("rebuilt open orders from state", zap.Uint64("height", blk.Height()))
+	}
+
 	batch := c.metaDB.NewBatch()
 	defer batch.Reset()
+	orders := newOpenOrders(c.metaDB)
 
 	results := blk.Results()
 	for i, tx := range blk.Txs {
@@ -179,6 +222,7 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 			blk.GetTimestamp(),
 			result.Success,
 			result.Units,
+			result.Output,
 		)
 		if err != nil {
 			return err
@@ -194,7 +238,8 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 			case *actions.CreateEnergyOrder:
 				c.metrics.createEnergyOrder.Inc()
 				actor := auth.GetActor(tx.Auth)
-				c.energyLedger.Add(tx.ID(), actor, action)
+				c.energyLedger.Add(tx.ID(), actor, action, blk.Height(), uint32(i))
+				orders.add(tx.ID(), actor, action, blk.Height(), uint32(i))
 			case *actions.FillEnergyOrder:
 				c.metrics.fillEnergyOrder.Inc()
 				orderResult, err := actions.UnmarshalOrderResult(result.Output)
@@ -202,7 +247,11 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 					// This should never happen
 					return err
 				}
-				if energyLedger.Remaining == 0 {
+				c.metrics.recordFees(action.In, orderResult.MakerFee, action.Out, orderResult.TakerFee)
+				if err := orders.fill(ctx, action.Order, orderResult.Remaining); err != nil {
+					return err
+				}
+				if orderResult.Remaining == 0 {
 					c.energyLedger.Remove(action.Order)
 					continue
 				}
@@ -210,9 +259,149 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 			case *actions.CloseEnergyOrder:
 				c.metrics.closeEnergyOrder.Inc()
 				c.energyLedger.Remove(action.Order)
+				orders.remove(action.Order)
+			case *actions.TransferEnergy:
+				c.metrics.transferEnergy.Inc()
+			case *actions.ExportEnergy:
+				c.metrics.exportEnergy.Inc()
+			case *actions.ImportEnergy:
+				c.metrics.importEnergy.Inc()
+			case *actions.RegisterMeter:
+				c.metrics.registerMeter.Inc()
+			case *actions.ProduceMeteredEnergy:
+				c.metrics.produceMeteredEnergy.Inc()
+			case *actions.TransferCertificate:
+				c.metrics.transferCertificate.Inc()
+			case *actions.RetireCertificate:
+				c.metrics.retireCertificate.Inc()
+			case *actions.ReclaimExpiredOrder:
+				c.metrics.reclaimExpiredOrder.Inc()
+				c.energyLedger.Remove(action.Order)
+				orders.remove(action.Order)
+			case *actions.SubmitLimitOrder:
+				c.metrics.submitLimitOrder.Inc()
+				limitResult, err := actions.UnmarshalLimitOrderResult(result.Output)
+				if err != nil {
+					// This should never happen
+					return err
+				}
+				for _, fill := range limitResult.Fills {
+					// The limit order pays [Out] to resting orders and
+					// receives [In]
+					c.metrics.recordFees(action.Out, fill.MakerFee, action.In, fill.TakerFee)
+					if err := orders.fill(ctx, fill.Order, fill.Remaining); err != nil {
+						return err
+					}
+					if fill.Remaining == 0 {
+						c.energyLedger.Remove(fill.Order)
+						continue
+					}
+					c.energyLedger.UpdateRemaining(fill.Order, fill.Remaining)
+				}
+				if limitResult.Listed > 0 {
+					actor := auth.GetActor(tx.Auth)
+					resting := action.RestingOrder(limitResult.Listed)
+					c.energyLedger.Add(tx.ID(), actor, resting, blk.Height(), uint32(i))
+					orders.add(tx.ID(), actor, resting, blk.Height(), uint32(i))
+				}
+			case *actions.FillBestEnergyOrders:
+				c.metrics.fillBestEnergyOrders.Inc()
+				ordersResult, err := actions.UnmarshalOrdersResult(result.Output)
+				if err != nil {
+					// This should never happen
+					return err
+				}
+				for _, fill := range ordersResult.Fills {
+					c.metrics.recordFees(action.In, fill.MakerFee, action.Out, fill.TakerFee)
+					if err := orders.fill(ctx, fill.Order, fill.Remaining); err != nil {
+						return err
+					}
+					if fill.Remaining == 0 {
+						c.energyLedger.Remove(fill.Order)
+						continue
+					}
+					c.energyLedger.UpdateRemaining(fill.Order, fill.Remaining)
+				}
+			case *actions.AmendEnergyOrder:
+				c.metrics.amendEnergyOrder.Inc()
+				if err := orders.amend(
+					ctx, action.Order, action.InTick, action.OutTick, action.Supply, blk.Height(), uint32(i),
+				); err != nil {
+					return err
+				}
+				if !c.energyLedger.Amend(
+					action.Order, action.InTick, action.OutTick, action.Supply, blk.Height(), uint32(i),
+				) {
+					// The order may have been dropped by the filters of a
+					// tracked pair and now pass them
+					order, err := orders.get(ctx, action.Order)
+					if err != nil {
+						return err
+					}
+					if order != nil {
+						addOpenOrder(c.energyLedger, order)
+					}
+				}
+			case *actions.GrantRole:
+				c.metrics.grantRole.Inc()
+			case *actions.RevokeRole:
+				c.metrics.revokeRole.Inc()
+			case *actions.UpdateAssetOwner:
+				c.metrics.updateAssetOwner.Inc()
+				if err := storage.StoreAssetChange(
+					ctx, batch, action.Asset, blk.Height(), uint32(i), tx.ID(), blk.GetTimestamp(),
+					storage.AssetChangeOwner, auth.GetActor(tx.Auth), action.Owner[:],
+				); err != nil {
+					return err
+				}
+			case *actions.UpdateAssetMetadata:
+				c.metrics.updateAssetMetadata.Inc()
+				if err := storage.StoreAssetChange(
+					ctx, batch, action.Asset, blk.Height(), uint32(i), tx.ID(), blk.GetTimestamp(),
+					storage.AssetChangeMetadata, auth.GetActor(tx.Auth), action.Metadata,
+				); err != nil {
+					return err
+				}
+			case *actions.FreezeAsset:
+				c.metrics.freezeAsset.Inc()
+				if err := storage.StoreAssetChange(
+					ctx, batch, action.Asset, blk.Height(), uint32(i), tx.ID(), blk.GetTimestamp(),
+					storage.AssetChangeFreeze, auth.GetActor(tx.Auth), nil,
+				); err != nil {
+					return err
+				}
+			case *actions.UnfreezeAsset:
+				c.metrics.unfreezeAsset.Inc()
+				if err := storage.StoreAssetChange(
+					ctx, batch, action.Asset, blk.Height(), uint32(i), tx.ID(), blk.GetTimestamp(),
+					storage.AssetChangeUnfreeze, auth.GetActor(tx.Auth), nil,
+				); err != nil {
+					return err
+				}
+			case *actions.RegisterSymbol:
+				c.metrics.registerSymbol.Inc()
+				if err := storage.StoreAssetChange(
+					ctx, batch, action.Asset, blk.Height(), uint32(i), tx.ID(), blk.GetTimestamp(),
+					storage.AssetChangeSymbol, auth.GetActor(tx.Auth), []byte(action.Symbol),
+				); err != nil {
+					return err
+				}
+				if err := storage.StoreSymbolCopy(ctx, batch, action.Symbol, action.Asset); err != nil {
+					return err
+				}
+				c.energyLedger.RegisterSymbol(action.Symbol, action.Asset)
+			case *actions.TransferMeter:
+				c.metrics.transferMeter.Inc()
 			}
 		}
 	}
+	c.energyLedger.Expire(blk.GetTimestamp())
+	if err := orders.write(ctx, batch); err != nil {
+		return err
+	}
+	if err := storage.StoreOrderIndexHeight(ctx, batch, blk.Height()); err != nil {
+		return err
+	}
 	return batch.Write()
 }
 
@@ -220,8 +409,13 @@ func (*Controller) Rejected(context.Context, *chain.StatelessBlock) error {
 	return nil
 }
 
-func (*Controller) Shutdown(context.Context) error {
+func (c *Controller) Shutdown(context.Context) error {
 	// Do not close any databases provided during initialization. The VM will
 	// close any databases your provided.
-	return nil
+	errs := wrappers.Errs{}
+	errs.Add(
+		c.streamer.Close(),
+		c.metaDB.Close(),
+	)
+	return errs.Err
 }
@@ -2,6 +2,7 @@ package controller
 
 import (
 	ametrics "github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/hypersdk/examples/tokenvm/consts"
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,6 +15,30 @@ type metrics struct {
 	createEnergyOrder     prometheus.Counter
 	fillEnergyOrder       prometheus.Counter
 	closeEnergyOrder      prometheus.Counter
+	transferEnergy        prometheus.Counter
+	exportEnergy          prometheus.Counter
+	importEnergy          prometheus.Counter
+	registerMeter         prometheus.Counter
+	produceMeteredEnergy  prometheus.Counter
+	transferCertificate   prometheus.Counter
+	retireCertificate     prometheus.Counter
+	reclaimExpiredOrder   prometheus.Counter
+	submitLimitOrder      prometheus.Counter
+	fillBestEnergyOrders  prometheus.Counter
+	amendEnergyOrder      prometheus.Counter
+	grantRole             prometheus.Counter
+	revokeRole            prometheus.Counter
+	updateAssetOwner      prometheus.Counter
+	updateAssetMetadata   prometheus.Counter
+	freezeAsset           prometheus.Counter
+	unfreezeAsset         prometheus.Counter
+	registerSymbol        prometheus.Counter
+	transferMeter         prometheus.Counter
+
+	makerFees *prometheus.CounterVec
+	takerFees *prometheus.CounterVec
+
+	droppedOrders *prometheus.CounterVec
 }
 
 func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
@@ -48,6 +73,116 @@ func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
 			Name:      "close_energy_order",
 			Help:      "number of close energy order actions",
 		}),
+		transferEnergy: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "transfer_energy",
+			Help:      "number of transfer energy actions",
+		}),
+		exportEnergy: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "export_energy",
+			Help:      "number of export energy actions",
+		}),
+		importEnergy: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "import_energy",
+			Help:      "number of import energy actions",
+		}),
+		registerMeter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "register_meter",
+			Help:      "number of register meter actions",
+		}),
+		produceMeteredEnergy: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "produce_metered_energy",
+			Help:      "number of produce metered energy actions",
+		}),
+		transferCertificate: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "transfer_certificate",
+			Help:      "number of transfer certificate actions",
+		}),
+		retireCertificate: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "retire_certificate",
+			Help:      "number of retire certificate actions",
+		}),
+		reclaimExpiredOrder: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "reclaim_expired_order",
+			Help:      "number of reclaim expired order actions",
+		}),
+		submitLimitOrder: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "submit_limit_order",
+			Help:      "number of submit limit order actions",
+		}),
+		fillBestEnergyOrders: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "fill_best_energy_orders",
+			Help:      "number of fill best energy orders actions",
+		}),
+		amendEnergyOrder: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "amend_energy_order",
+			Help:      "number of amend energy order actions",
+		}),
+		grantRole: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "grant_role",
+			Help:      "number of grant role actions",
+		}),
+		revokeRole: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "revoke_role",
+			Help:      "number of revoke role actions",
+		}),
+		updateAssetOwner: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "update_asset_owner",
+			Help:      "number of update asset owner actions",
+		}),
+		updateAssetMetadata: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "update_asset_metadata",
+			Help:      "number of update asset metadata actions",
+		}),
+		freezeAsset: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "freeze_asset",
+			Help:      "number of freeze asset actions",
+		}),
+		unfreezeAsset: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "unfreeze_asset",
+			Help:      "number of unfreeze asset actions",
+		}),
+		registerSymbol: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "register_symbol",
+			Help:      "number of register symbol actions",
+		}),
+		transferMeter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "transfer_meter",
+			Help:      "number of transfer meter actions",
+		}),
+		makerFees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "fees",
+			Name:      "maker",
+			Help:      "cumulative maker fees collected per asset",
+		}, []string{"asset"}),
+		takerFees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "fees",
+			Name:      "taker",
+			Help:      "cumulative taker fees collected per asset",
+		}, []string{"asset"}),
+		droppedOrders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "energy_ledger",
+			Name:      "dropped_orders",
+			Help:      "number of orders dropped from tracked pairs per reason",
+		}, []string{"reason"}),
 	}
 	r := prometheus.NewRegistry()
 	errs := wrappers.Errs{}
@@ -58,7 +193,39 @@ func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
 		r.Register(m.createEnergyOrder),
 		r.Register(m.fillEnergyOrder),
 		r.Register(m.closeEnergyOrder),
+		r.Register(m.transferEnergy),
+		r.Register(m.exportEnergy),
+		r.Register(m.importEnergy),
+		r.Register(m.registerMeter),
+		r.Register(m.produceMeteredEnergy),
+		r.Register(m.transferCertificate),
+		r.Register(m.retireCertificate),
+		r.Register(m.reclaimExpiredOrder),
+		r.Register(m.submitLimitOrder),
+		r.Register(m.fillBestEnergyOrders),
+		r.Register(m.amendEnergyOrder),
+		r.Register(m.grantRole),
+		r.Register(m.revokeRole),
+		r.Register(m.updateAssetOwner),
+		r.Register(m.updateAssetMetadata),
+		r.Register(m.freezeAsset),
+		r.Register(m.unfreezeAsset),
+		r.Register(m.registerSymbol),
+		r.Register(m.transferMeter),
+		r.Register(m.makerFees),
+		r.Register(m.takerFees),
+		r.Register(m.droppedOrders),
 		gatherer.Register(consts.Name, r),
 	)
 	return m, errs.Err
 }
+
+// recordFees adds the fees collected by a fill to the per-asset totals.
+func (m *metrics) recordFees(makerAsset ids.ID, makerFee uint64, takerAsset ids.ID, takerFee uint64) {
+	if makerFee > 0 {
+		m.makerFees.WithLabelValues(makerAsset.String()).Add(float64(makerFee))
+	}
+	if takerFee > 0 {
+		m.takerFees.WithLabelValues(takerAsset.String()).Add(float64(takerFee))
+	}
+}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+// openOrders collects the changes a block makes to the open orders kept in
+// the metaDB and to the index of them by owner. Changes stay in memory until
+// [write] so an order that is listed and filled in the same block is indexed
+// correctly.
+type openOrders struct {
+	db      database.KeyValueReader
+	changed map[ids.ID]*storage.OpenOrder // nil if the order was removed
+}
+
+func newOpenOrders(db database.KeyValueReader) *openOrders {
+	return &openOrders{
+		db:      db,
+		changed: map[ids.ID]*storage.OpenOrder{},
+	}
+}
+
+func (o *openOrders) add(
+	txID ids.ID,
+	actor crypto.PublicKey,
+	action *actions.CreateEnergyOrder,
+	height uint64,
+	index uint32,
+) {
+	o.changed[txID] = &storage.OpenOrder{
+		ID:        txID,
+		Side:      action.Side,
+		In:        action.In,
+		InTick:    action.InTick,
+		Out:       action.Out,
+		OutTick:   action.OutTick,
+		Remaining: action.Supply,
+		Owner:     actor,
+		Expiry:    action.Expiry,
+		AllOrNone: action.AllOrNone,
+		MinFill:   action.MinFill,
+		Height:    height,
+		Index:     index,
+	}
+}
+
+func (o *openOrders) get(ctx context.Context, id ids.ID) (*storage.OpenOrder, error) {
+	if order, ok := o.changed[id]; ok {
+		return order, nil
+	}
+	return storage.GetOpenOrder(ctx, o.db, id)
+}
+
+// fill sets the [remaining] supply of [id], removing it once it is filled.
+func (o *openOrders) fill(ctx context.Context, id ids.ID, remaining uint64) error {
+	if remaining == 0 {
+		o.remove(id)
+		return nil
+	}
+	return o.update(ctx, id, func(order *storage.OpenOrder) {
+		order.Remaining = remaining
+	})
+}
+
+func (o *openOrders) amend(
+	ctx context.Context,
+	id ids.ID,
+	inTick uint64,
+	outTick uint64,
+	remaining uint64,
+	height uint64,
+	index uint32,
+) error {
+	return o.update(ctx, id, func(order *storage.OpenOrder) {
+		if !actions.KeepsPriority(order.InTick, order.OutTick, order.Remaining, inTick, outTick, remaining) {
+			order.Height = height
+			order.Index = index
+		}
+		order.InTick = inTick
+		order.OutTick = outTick
+		order.Remaining = remaining
+	})
+}
+
+func (o *openOrders) update(ctx context.Context, id ids.ID, f func(*storage.OpenOrder)) error {
+	order, err := o.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		// The order was removed
+		return nil
+	}
+	f(order)
+	o.changed[id] = order
+	return nil
+}
+
+func (o *openOrders) remove(id ids.ID) {
+	o.changed[id] = nil
+}
+
+func (o *openOrders) write(ctx context.Context, db database.KeyValueWriterDeleter) error {
+	for id, order := range o.changed {
+		if order == nil {
+			stored, err := storage.GetOpenOrder(ctx, o.db, id)
+			if err != nil {
+				return err
+			}
+			if stored == nil {
+				// The order was listed in this block
+				continue
+			}
+			if err := storage.DeleteOwnerOrder(ctx, db, stored.Owner, id); err != nil {
+				return err
+			}
+			if err := storage.DeleteOpenOrder(ctx, db, id); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := storage.StoreOwnerOrder(ctx, db, order.Owner, id); err != nil {
+			return err
+		}
+		if err := storage.StoreOpenOrder(ctx, db, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadOpenOrders adds every order in the metaDB to [ledger]. It is called
+// before the node serves RPC so the book survives restarts.
+func loadOpenOrders(ctx context.Context, db database.Database, ledger *energyledger.EnergyLedger) error {
+	orders, err := storage.GetOpenOrders(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		addOpenOrder(ledger, order)
+	}
+	return nil
+}
+
+// rebuildOpenOrders replaces the open orders in [db] and [ledger] with the
+// orders in [state]. It is called when the metaDB is missing blocks, as it is
+// after state sync or if it was written before the open orders were kept
+// there. State doesn't record when orders were listed, so they are given the
+// time priority of the block at [height] in the order of their IDs.
+func rebuildOpenOrders(
+	ctx context.Context,
+	db database.Database,
+	state database.Iteratee,
+	ledger *energyledger.EnergyLedger,
+	height uint64,
+) error {
+	batch := db.NewBatch()
+	defer batch.Reset()
+
+	stale, err := storage.GetOpenOrders(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, order := range stale {
+		if err := storage.DeleteOwnerOrder(ctx, batch, order.Owner, order.ID); err != nil {
+			return err
+		}
+		if err := storage.DeleteOpenOrder(ctx, batch, order.ID); err != nil {
+			return err
+		}
+		ledger.Remove(order.ID)
+	}
+	orders, err := storage.GetEnergyOrders(ctx, state)
+	if err != nil {
+		return err
+	}
+	for i, order := range orders {
+		order.Height = height
+		order.Index = uint32(i)
+		if err := storage.StoreOwnerOrder(ctx, batch, order.Owner, order.ID); err != nil {
+			return err
+		}
+		if err := storage.StoreOpenOrder(ctx, batch, order); err != nil {
+			return err
+		}
+		addOpenOrder(ledger, order)
+	}
+	if err := storage.StoreOrderIndexHeight(ctx, batch, height); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func addOpenOrder(ledger *energyledger.EnergyLedger, order *storage.OpenOrder) {
+	ledger.Add(order.ID, order.Owner, &actions.CreateEnergyOrder{
+		Side:      order.Side,
+		In:        order.In,
+		InTick:    order.InTick,
+		Out:       order.Out,
+		OutTick:   order.OutTick,
+		Supply:    order.Remaining,
+		Expiry:    order.Expiry,
+		AllOrNone: order.AllOrNone,
+		MinFill:   order.MinFill,
+	}, order.Height, order.Index)
+}
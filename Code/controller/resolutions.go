@@ -27,14 +27,14 @@ func (c *Controller) Tracer() trace.Tracer {
 func (c *Controller) GetTransaction(
 	ctx context.Context,
 	txID ids.ID,
-) (bool, int64, bool, uint64, error) {
+) (bool, int64, bool, uint64, []byte, error) {
 	return storage.GetTransaction(ctx, c.metaDB, txID)
 }
 
 func (c *Controller) GetAssetFromState(
 	ctx context.Context,
 	asset ids.ID,
-) (bool, []byte, uint64, crypto.PublicKey, bool, error) {
+) (bool, []byte, uint64, crypto.PublicKey, bool, bool, error) {
 	return storage.GetAssetFromState(ctx, c.inner.ReadState, asset)
 }
 
@@ -46,8 +46,12 @@ func (c *Controller) GetBalanceFromState(
 	return storage.GetBalanceFromState(ctx, c.inner.ReadState, pk, asset)
 }
 
-func (c *Controller) Orders(pair string, limit int) []*energyledger.EnergyOrder {
-	return c.energyLedger.Orders(pair, limit)
+func (c *Controller) Orders(pair string, quantity uint64, limit int) *energyledger.Book {
+	return c.energyLedger.Orders(pair, quantity, limit)
+}
+
+func (c *Controller) Depth(pair string, levels int) *energyledger.Depth {
+	return c.energyLedger.Depth(pair, levels)
 }
 
 func (c *Controller) GetCreditFromState(
@@ -57,3 +61,71 @@ func (c *Controller) GetCreditFromState(
 ) (uint64, error) {
 	return storage.GetCreditFromState(ctx, c.inner.ReadState, asset, destination)
 }
+
+func (c *Controller) GetCertificateFromState(
+	ctx context.Context,
+	certificate ids.ID,
+) (bool, ids.ID, []byte, []byte, int64, int64, crypto.PublicKey, bool, []byte, error) {
+	return storage.GetCertificateFromState(ctx, c.inner.ReadState, certificate)
+}
+
+func (c *Controller) GetAssetChanges(ctx context.Context, asset ids.ID) ([]*storage.AssetChange, error) {
+	return storage.GetAssetChanges(ctx, c.metaDB, asset)
+}
+
+// GetOwnerOrders returns up to [limit] open orders of [owner] that come after
+// [cursor], ordered by ID.
+func (c *Controller) GetOwnerOrders(
+	ctx context.Context,
+	owner crypto.PublicKey,
+	cursor ids.ID,
+	limit int,
+) ([]*storage.OpenOrder, error) {
+	orderIDs, err := storage.GetOwnerOrders(ctx, c.metaDB, owner, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*storage.OpenOrder, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		order, err := storage.GetOpenOrder(ctx, c.metaDB, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if order == nil {
+			// Removed after the index was read
+			continue
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (c *Controller) GetRolesFromState(ctx context.Context, pk crypto.PublicKey) (uint8, error) {
+	return storage.GetRolesFromState(ctx, c.inner.ReadState, pk)
+}
+
+func (c *Controller) GetSymbolAssetFromState(ctx context.Context, symbol string) (bool, ids.ID, error) {
+	return storage.GetSymbolAssetFromState(ctx, c.inner.ReadState, symbol)
+}
+
+func (c *Controller) GetAssetSymbolFromState(ctx context.Context, asset ids.ID) (bool, string, error) {
+	return storage.GetAssetSymbolFromState(ctx, c.inner.ReadState, asset)
+}
+
+// GetSymbolAsset is [GetSymbolAssetFromState] but also works before state
+// can be read, as symbols can't change once they are registered.
+func (c *Controller) GetSymbolAsset(ctx context.Context, symbol string) (bool, ids.ID, error) {
+	exists, asset, err := storage.GetSymbolCopy(ctx, c.metaDB, symbol)
+	if err != nil || exists {
+		return exists, asset, err
+	}
+	return storage.GetSymbolAssetFromState(ctx, c.inner.ReadState, symbol)
+}
+
+func (c *Controller) RecordDroppedOrder(reason string) {
+	c.metrics.droppedOrders.WithLabelValues(reason).Inc()
+}
+
+func (c *Controller) RecordOrderEvent(event string, pair string, order *energyledger.EnergyOrder) {
+	c.streamer.OrderEvent(event, pair, order)
+}
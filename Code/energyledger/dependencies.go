@@ -1,9 +1,15 @@
 package energyledger
 
 import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 )
 
 type Controller interface {
 	Logger() logging.Logger
+	GetSymbolAsset(context.Context, string) (bool, ids.ID, error)
+	RecordDroppedOrder(reason string)
+	RecordOrderEvent(event string, pair string, order *EnergyOrder)
 }
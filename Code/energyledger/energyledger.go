@@ -1,12 +1,20 @@
 package energyledger
 
 import (
+	"context"
+	"errors"
+	"math/bits"
+	"sort"
 	"sync"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/ava-labs/hypersdk/heap"
 	"go.uber.org/zap"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/config"
+	"github.com/bbehrman10/energyavavm/utils"
 )
 
 const (
@@ -14,122 +22,528 @@ const (
 	allPairs            = "*"
 )
 
+// Changes to the listed orders reported to [Controller.RecordOrderEvent].
+const (
+	EventAdd    = "add"
+	EventUpdate = "update"
+	EventRemove = "remove"
+)
+
 type EnergyOrder struct {
 	ID           ids.ID `json:"id"`
+	Side         uint8  `json:"side"`
 	Producer     string `json:"producer"`
 	EnergyAmount uint64 `json:"energyAmount"`
 	TokensPaid   uint64 `json:"tokensPaid"`
 	Remaining    uint64 `json:"remaining"`
+	Expiry       int64  `json:"expiry"`
+	AllOrNone    bool   `json:"allOrNone"`
+	MinFill      uint64 `json:"minFill"`
+
+	// Height is the height of the block the order was listed or last
+	// repriced or grown in. Orders at the same price are filled in the order
+	// they got there.
+	Height uint64 `json:"height"`
+
+	producer crypto.PublicKey
+	index    uint32 // of the tx in the block at [Height]
+	pair     string
+}
+
+// price returns the price of 1 unit of energy as [tokens]/[energy]. Asks
+// receive currency for energy and bids receive energy for currency, so it is
+// inverted for bids.
+func (e *EnergyOrder) price() (tokens uint64, energy uint64) {
+	if e.Side == actions.SideBid {
+		return e.TokensPaid, e.EnergyAmount
+	}
+	return e.EnergyAmount, e.TokensPaid
+}
+
+// comparePrice returns -1, 0 or 1 if the price of [a] is lower than, equal to
+// or higher than the price of [b]. Prices are compared as exact fractions so
+// no precision is lost to rounding.
+func comparePrice(a *EnergyOrder, b *EnergyOrder) int {
+	aTokens, aEnergy := a.price()
+	bTokens, bEnergy := b.price()
+	return compareFractions(aTokens, aEnergy, bTokens, bEnergy)
+}
+
+// compareFractions returns -1, 0 or 1 if [aNum]/[aDen] is lower than, equal
+// to or higher than [bNum]/[bDen].
+func compareFractions(aNum uint64, aDen uint64, bNum uint64, bDen uint64) int {
+	aHi, aLo := bits.Mul64(aNum, bDen)
+	bHi, bLo := bits.Mul64(bNum, aDen)
+	switch {
+	case aHi < bHi || (aHi == bHi && aLo < bLo):
+		return -1
+	case aHi == bHi && aLo == bLo:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// Book is the two-sided view of a pair. Each side is sorted from the best
+// order to the worst: bids by highest price and asks by lowest price, with
+// ties going to the order that got to the price first.
+type Book struct {
+	Bids []*EnergyOrder `json:"bids"`
+	Asks []*EnergyOrder `json:"asks"`
+}
+
+// Level is every order at one price on a side of a book. The price is
+// [Tokens] of currency per [Energy] units of energy in lowest terms.
+type Level struct {
+	Tokens uint64 `json:"tokens"`
+	Energy uint64 `json:"energy"`
+
+	// Remaining is the total remaining supply of the orders, denominated in
+	// the asset they lock up.
+	Remaining uint64 `json:"remaining"`
+	Orders    int    `json:"orders"`
+}
+
+// Depth is the aggregated view of a pair, sorted the same way as [Book].
+type Depth struct {
+	Bids []*Level `json:"bids"`
+	Asks []*Level `json:"asks"`
+}
 
-	producer crypto.PrivateKey
+// book holds both sides of a pair. Both sides are ranked by the price of 1
+// unit of energy so bids and asks can be compared.
+type book struct {
+	filter *config.TrackedPair
+	bids   *side
+	asks   *side
+}
+
+func newBook(filter *config.TrackedPair) *book {
+	return &book{
+		filter: filter,
+		bids:   &side{bid: true, orders: make([]*EnergyOrder, 0, initialPairCapacity)},
+		asks:   &side{orders: make([]*EnergyOrder, 0, initialPairCapacity)},
+	}
+}
+
+func (b *book) len() int {
+	return len(b.bids.orders) + len(b.asks.orders)
+}
+
+func (b *book) side(s uint8) *side {
+	if s == actions.SideBid {
+		return b.bids
+	}
+	return b.asks
+}
+
+// side is one side of a book, sorted from the best order to the worst.
+type side struct {
+	bid    bool
+	orders []*EnergyOrder
+}
+
+// before returns true if [a] should be filled before [b].
+func (s *side) before(a *EnergyOrder, b *EnergyOrder) bool {
+	if c := comparePrice(a, b); c != 0 {
+		return (c > 0) == s.bid
+	}
+	if a.Height != b.Height {
+		return a.Height < b.Height
+	}
+	return a.index < b.index
+}
+
+func (s *side) insert(order *EnergyOrder) {
+	i := sort.Search(len(s.orders), func(i int) bool {
+		return s.before(order, s.orders[i])
+	})
+	s.orders = append(s.orders, nil)
+	copy(s.orders[i+1:], s.orders[i:])
+	s.orders[i] = order
+}
+
+func (s *side) remove(order *EnergyOrder) {
+	i := sort.Search(len(s.orders), func(i int) bool {
+		return !s.before(s.orders[i], order)
+	})
+	for ; i < len(s.orders); i++ {
+		if s.orders[i] == order {
+			s.orders = append(s.orders[:i], s.orders[i+1:]...)
+			return
+		}
+	}
 }
 
 type EnergyLedger struct {
-	c *Controller
+	c Controller
+
+	orders   map[string]*book
+	byID     map[ids.ID]*EnergyOrder
+	expiries *heap.Heap[*EnergyOrder, int64] // min heap of orders that can expire
+	l        sync.Mutex
 
-	orders      map[string]*heap.Heap[*EnergyOrder, float64]
-	orderToPair map[ids.ID]string
-	l           sync.Mutex
+	// trackAll holds the filters of every pair if all pairs are tracked.
+	trackAll *config.TrackedPair
 
-	trackAll bool
+	// pending are the tracked pairs that name a symbol which could not be
+	// resolved yet. They are retried when symbols are registered and, if a
+	// lookup failed, on the next order added.
+	pending  []*config.TrackedPair
+	resolved bool
 }
 
-func NewEnergyLedger(c *Controller, trackedPairs []string) *EnergyLedger {
-	m := map[string]*heap.Heap[*EnergyOrder, float64]{}
-	trackAll := false
-	if len(trackedPairs) == 1 && trackedPairs[0] == allPairs {
-		trackAll = true
+func NewEnergyLedger(c Controller, trackedPairs []*config.TrackedPair) *EnergyLedger {
+	m := map[string]*book{}
+	var trackAll *config.TrackedPair
+	pending := []*config.TrackedPair{}
+	if len(trackedPairs) == 1 && trackedPairs[0].Pair == allPairs {
+		trackAll = trackedPairs[0]
 		c.Logger().Info("tracking all energy ledgers")
 	} else {
 		for _, pair := range trackedPairs {
-			//Must use a max heap to return best rates
-			m[pair] = heap.New[*EnergyOrder, float64](initialPairCapacity, true)
-			c.Logger().Info("tracking energy ledger", zap.String("pair", pair))
+			id, err := actions.ParsePair(pair.Pair, nil)
+			switch {
+			case errors.Is(err, actions.ErrSymbolMissing):
+				pending = append(pending, pair)
+			case err != nil:
+				c.Logger().Warn("ignoring tracked pair", zap.String("pair", pair.Pair), zap.Error(err))
+			default:
+				m[id] = newBook(pair)
+				c.Logger().Info("tracking energy ledger", zap.String("pair", id))
+			}
 		}
 	}
 	return &EnergyLedger{
-		c:           c,
-		orders:      m,
-		orderToPair: map[ids.ID]string{},
-		trackAll:    trackAll,
+		c:        c,
+		orders:   m,
+		byID:     map[ids.ID]*EnergyOrder{},
+		expiries: heap.New[*EnergyOrder, int64](initialPairCapacity, true),
+		trackAll: trackAll,
+		pending:  pending,
 	}
 }
 
-func (o *EnergyLedger) Add(pair string, order *EnergyOrder) {
+// RegisterSymbol resolves any pending tracked pairs that name [symbol].
+func (o *EnergyLedger) RegisterSymbol(symbol string, asset ids.ID) {
 	o.l.Lock()
 	defer o.l.Unlock()
-	h, ok := o.orders[pair]
+	o.resolve(func(s string) (ids.ID, bool, error) {
+		if s == symbol {
+			return asset, true, nil
+		}
+		return o.lookupSymbol(s)
+	})
+}
+
+func (o *EnergyLedger) lookupSymbol(symbol string) (ids.ID, bool, error) {
+	exists, asset, err := o.c.GetSymbolAsset(context.Background(), symbol)
+	return asset, exists, err
+}
+
+// resolve starts tracking every pending pair whose symbols are registered.
+// If state can't be read yet, it is retried on the next order added.
+func (o *EnergyLedger) resolve(lookup actions.SymbolLookup) {
+	pending := o.pending[:0]
+	resolved := true
+	for _, pair := range o.pending {
+		id, err := actions.ParsePair(pair.Pair, lookup)
+		if err != nil {
+			if !errors.Is(err, actions.ErrSymbolMissing) {
+				o.c.Logger().Debug("unable to resolve tracked pair", zap.String("pair", pair.Pair), zap.Error(err))
+				resolved = false
+			}
+			pending = append(pending, pair)
+			continue
+		}
+		if _, ok := o.orders[id]; !ok {
+			o.orders[id] = newBook(pair)
+		}
+		o.c.Logger().Info("tracking energy ledger", zap.String("pair", id), zap.String("symbols", pair.Pair))
+	}
+	o.pending = pending
+	o.resolved = resolved
+}
+
+// Add lists [action], which was the [index]th tx of the block at [height]. If
+// [txID] is already listed, as it can be after the ledger is rebuilt from
+// state, it is replaced.
+func (o *EnergyLedger) Add(
+	txID ids.ID,
+	actor crypto.PublicKey,
+	action *actions.CreateEnergyOrder,
+	height uint64,
+	index uint32,
+) {
+	pair := actions.BookID(action.Side, action.In, action.Out)
+	order := &EnergyOrder{
+		txID,
+		action.Side,
+		utils.Address(actor),
+		action.InTick,
+		action.OutTick,
+		action.Supply,
+		action.Expiry,
+		action.AllOrNone,
+		action.MinFill,
+		height,
+		actor,
+		index,
+		pair,
+	}
+
+	o.l.Lock()
+	defer o.l.Unlock()
+	o.remove(txID)
+	if !o.resolved && len(o.pending) > 0 {
+		o.resolve(o.lookupSymbol)
+	}
+	b, ok := o.orders[pair]
 	switch {
-	case !ok && !o.trackAll:
+	case !ok && o.trackAll == nil:
 		return
-	case !ok && o.trackAll:
+	case !ok:
 		o.c.Logger().Info("tracking energy ledger", zap.String("pair", pair))
-		h = heap.New[*EnergyOrder, float64](initialPairCapacity, true)
-		o.orders[pair] = h
-		h.Push(&heap.Entry[*EnergyOrder, float64]{
+		b = newBook(o.trackAll)
+		o.orders[pair] = b
+	}
+	if reason := rejectReason(b.filter, order); len(reason) > 0 {
+		o.c.RecordDroppedOrder(reason)
+		return
+	}
+	b.side(order.Side).insert(order)
+	o.byID[order.ID] = order
+	o.notify(EventAdd, order)
+	if order.Expiry != 0 {
+		o.expiries.Push(&heap.Entry[*EnergyOrder, int64]{
 			ID:    order.ID,
-			Val:   float64(order.EnergyAmount) / float64(order.TokensPaid),
+			Val:   order.Expiry,
 			Item:  order,
-			Index: h.Len(),
+			Index: o.expiries.Len(),
 		})
-		o.orderToPair[order.ID] = pair
 	}
+	o.trim(b)
+}
+
+// trim drops the worst priced orders of [b] until it holds no more than the
+// maximum number of orders of its filter. Orders are taken from whichever
+// side is longer.
+func (o *EnergyLedger) trim(b *book) {
+	if b.filter.MaxOrders == 0 {
+		return
+	}
+	for b.len() > b.filter.MaxOrders {
+		s := b.asks
+		if len(b.bids.orders) > len(b.asks.orders) {
+			s = b.bids
+		}
+		o.drop(s.orders[len(s.orders)-1].ID, DropMaxOrders)
+	}
+}
+
+// drop removes [id] from the ledger and records why it was dropped.
+func (o *EnergyLedger) drop(id ids.ID, reason string) {
+	o.remove(id)
+	o.c.RecordDroppedOrder(reason)
 }
 
 func (o *EnergyLedger) Remove(id ids.ID) {
 	o.l.Lock()
 	defer o.l.Unlock()
-	pair, ok := o.orderToPair[id]
+	o.remove(id)
+}
+
+func (o *EnergyLedger) remove(id ids.ID) {
+	if entry, ok := o.expiries.Get(id); ok {
+		o.expiries.Remove(entry.Index)
+	}
+	side, order, ok := o.get(id)
 	if !ok {
 		return
 	}
-	delete(o.orderToPair, id)
-	h, ok := o.orders[pair]
+	delete(o.byID, id)
+	side.remove(order)
+	o.notify(EventRemove, order)
+}
+
+// notify reports [event] with a copy of [order], as the ledger keeps
+// changing the original.
+func (o *EnergyLedger) notify(event string, order *EnergyOrder) {
+	cp := *order
+	o.c.RecordOrderEvent(event, order.pair, &cp)
+}
+
+// get returns the side of the book [id] is listed on along with the order.
+func (o *EnergyLedger) get(id ids.ID) (*side, *EnergyOrder, bool) {
+	order, ok := o.byID[id]
 	if !ok {
-		//should never happen
-		return
+		return nil, nil, false
 	}
-	entry, ok := h.Get(id) // 0(log 1)
+	b, ok := o.orders[order.pair]
 	if !ok {
 		//should never happen
-		return
+		return nil, nil, false
+	}
+	return b.side(order.Side), order, true
+}
+
+// Expire drops all orders that can no longer be filled at [timestamp]. It
+// should be called with the timestamp of each accepted block.
+func (o *EnergyLedger) Expire(timestamp int64) {
+	o.l.Lock()
+	defer o.l.Unlock()
+	for o.expiries.Len() > 0 {
+		first := o.expiries.First()
+		if !actions.Expired(first.Val, timestamp) {
+			return
+		}
+		o.remove(first.ID)
 	}
-	h.Remove(entry.Index) // 0(log n)
 }
 
 func (o *EnergyLedger) UpdateRemaining(id ids.ID, remaining uint64) {
 	o.l.Lock()
 	defer o.l.Unlock()
-	pair, ok := o.orderToPair[id]
+	_, order, ok := o.get(id)
 	if !ok {
 		return
 	}
-	h, ok := o.orders[pair]
-	if !ok {
+	order.Remaining = remaining
+	if belowMinRemaining(order, o.orders[order.pair].filter.MinRemaining) {
+		o.drop(id, DropMinRemaining)
 		return
 	}
-	entry, ok := h.Get(id)
+	o.notify(EventUpdate, order)
+}
+
+// Amend replaces the price and remaining supply of [id] and moves it to its
+// new place in the book. An order that keeps its rate and does not grow keeps
+// its time priority (see [actions.KeepsPriority]). Otherwise, like a new
+// order, it goes behind the orders already at its price, as it was amended by
+// the [index]th tx of the block at [height].
+//
+// Amend returns false if [id] is not listed. The order may have been dropped
+// by the filters of its pair, so the caller should [Add] it again with the
+// amended values to check it against them.
+func (o *EnergyLedger) Amend(
+	id ids.ID,
+	inTick uint64,
+	outTick uint64,
+	remaining uint64,
+	height uint64,
+	index uint32,
+) bool {
+	o.l.Lock()
+	defer o.l.Unlock()
+	side, order, ok := o.get(id)
 	if !ok {
-		return
+		return false
+	}
+	side.remove(order)
+	if !actions.KeepsPriority(order.EnergyAmount, order.TokensPaid, order.Remaining, inTick, outTick, remaining) {
+		order.Height = height
+		order.index = index
 	}
-	entry.Item.Remaining = remaining
+	order.EnergyAmount = inTick
+	order.TokensPaid = outTick
+	order.Remaining = remaining
+	side.insert(order)
+	if reason := rejectReason(o.orders[order.pair].filter, order); len(reason) > 0 {
+		o.drop(id, reason)
+		return true
+	}
+	o.notify(EventUpdate, order)
+	return true
 }
 
-func (o *EnergyLedger) Orders(pair string, limit int) []*EnergyOrder {
+// CanFill returns true if a taker that wants [quantity] of the locked asset
+// can fill the order. A [quantity] of 0 matches any order.
+func (e *EnergyOrder) CanFill(quantity uint64) bool {
+	switch {
+	case quantity == 0 || quantity >= e.Remaining:
+		return true
+	case e.AllOrNone:
+		return false
+	default:
+		return quantity >= e.TokensPaid && quantity >= e.MinFill
+	}
+}
+
+// Orders returns up to [limit] orders from each side of [pair] that a taker
+// wanting [quantity] can fill. A [quantity] of 0 returns all orders.
+func (o *EnergyLedger) Orders(pair string, quantity uint64, limit int) *Book {
 	o.l.Lock()
 	defer o.l.Unlock()
-	h, ok := o.orders[pair]
+	b, ok := o.orders[pair]
 	if !ok {
-		return nil
+		return &Book{}
 	}
-	items := h.Items()
-	arrLen := len(items)
-	if limit < arrLen {
-		arrLen = limit
+	return &Book{
+		Bids: items(b.bids, quantity, limit),
+		Asks: items(b.asks, quantity, limit),
 	}
-	orders := make([]*EnergyOrder, arrLen)
-	for i := 0; i < arrLen; i++ {
-		orders[i] = items[i].Item
+}
+
+// items returns copies of up to [limit] orders of [s] that can be filled, so
+// they can be read once [Orders] releases the lock.
+func items(s *side, quantity uint64, limit int) []*EnergyOrder {
+	orders := []*EnergyOrder{}
+	for _, order := range s.orders {
+		if len(orders) == limit {
+			break
+		}
+		if !order.CanFill(quantity) {
+			continue
+		}
+		cp := *order
+		orders = append(orders, &cp)
 	}
 	return orders
 }
+
+// Depth returns up to [levels] price levels from each side of [pair].
+func (o *EnergyLedger) Depth(pair string, levels int) *Depth {
+	o.l.Lock()
+	defer o.l.Unlock()
+	b, ok := o.orders[pair]
+	if !ok {
+		return &Depth{Bids: []*Level{}, Asks: []*Level{}}
+	}
+	return &Depth{
+		Bids: aggregate(b.bids, levels),
+		Asks: aggregate(b.asks, levels),
+	}
+}
+
+func aggregate(s *side, levels int) []*Level {
+	depth := []*Level{}
+	var last *EnergyOrder
+	for _, order := range s.orders {
+		if last != nil && comparePrice(last, order) == 0 {
+			level := depth[len(depth)-1]
+			level.Remaining += order.Remaining
+			level.Orders++
+			continue
+		}
+		if len(depth) == levels {
+			break
+		}
+		tokens, energy := order.price()
+		d := gcd(tokens, energy)
+		depth = append(depth, &Level{
+			Tokens:    tokens / d,
+			Energy:    energy / d,
+			Remaining: order.Remaining,
+			Orders:    1,
+		})
+		last = order
+	}
+	return depth
+}
+
+func gcd(a uint64, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,42 @@
+package energyledger
+
+import (
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/config"
+)
+
+// Reasons an order is dropped from a tracked pair.
+const (
+	DropMinRemaining = "min_remaining"
+	DropPriceBand    = "price_band"
+	DropMaxOrders    = "max_orders"
+)
+
+// rejectReason returns why [order] can't be listed under [filter] or the
+// empty string if it can.
+func rejectReason(filter *config.TrackedPair, order *EnergyOrder) string {
+	if belowMinRemaining(order, filter.MinRemaining) {
+		return DropMinRemaining
+	}
+	tokens, energy := order.price()
+	if filter.MinPrice > 0 && compareFractions(tokens, energy, filter.MinPrice, 1) < 0 {
+		return DropPriceBand
+	}
+	if filter.MaxPrice > 0 && compareFractions(tokens, energy, filter.MaxPrice, 1) > 0 {
+		return DropPriceBand
+	}
+	return ""
+}
+
+// belowMinRemaining returns true if [order] has less than [min] energy left.
+// Bids lock currency, so their remaining supply is converted to energy at
+// the price of the bid.
+func belowMinRemaining(order *EnergyOrder, min uint64) bool {
+	if min == 0 {
+		return false
+	}
+	if order.Side == actions.SideBid {
+		return compareFractions(order.Remaining, order.TokensPaid, min, order.EnergyAmount) < 0
+	}
+	return order.Remaining < min
+}
@@ -2,4 +2,15 @@ package genesis
 
 const (
 	StateLockupField = "state_lockup"
+
+	// Keys of the trading fee parameters returned by [Rules.FetchCustom].
+	MakerFeeBipsField = "maker_fee_bips"
+	TakerFeeBipsField = "taker_fee_bips"
+	TreasuryField     = "treasury"
+
+	// Key of whether roles are required, returned by [Rules.FetchCustom].
+	PermissionedField = "permissioned"
+
+	// MaxFeeBips is a fee of 100%.
+	MaxFeeBips = 10_000
 )
@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrInvalidTarget      = errors.New("invalid target")
 	ErrStateLockupMissing = errors.New("state lockup parameter missing")
+	ErrInvalidFee         = errors.New("invalid fee")
+	ErrInvalidTreasury    = errors.New("invalid treasury")
+	ErrInvalidOperator    = errors.New("invalid operator")
 )
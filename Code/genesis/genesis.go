@@ -50,8 +50,16 @@ type Genesis struct {
 	WarpBaseFee      uint64 `json:"warpBaseFee"`
 	WarpFeePerSigner uint64 `json:"warpFeePerSigner"`
 
+	// Trading fees
+	MakerFeeBips uint64 `json:"makerFeeBips"` // taken from the proceeds of the order owner
+	TakerFeeBips uint64 `json:"takerFeeBips"` // taken from the amount received by the filler
+	Treasury     string `json:"treasury"`     // bech32 address that collects trading fees
+
 	// Allocations
 	CustomAllocation []*CustomAllocation `json:"customAllocation"`
+
+	// Roles
+	Operators []string `json:"operators"` // bech32 addresses; if empty, no roles are required
 }
 
 func Default() *Genesis {
@@ -95,6 +103,21 @@ func New(b []byte, _ []byte /* upgradeBytes */) (*Genesis, error) {
 	if g.WindowTargetBlocks == 0 {
 		return nil, ErrInvalidTarget
 	}
+	if g.MakerFeeBips > MaxFeeBips || g.TakerFeeBips > MaxFeeBips {
+		return nil, ErrInvalidFee
+	}
+	if len(g.Treasury) > 0 {
+		if _, err := utils.ParseAddress(g.Treasury); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTreasury, err)
+		}
+	} else if g.MakerFeeBips > 0 || g.TakerFeeBips > 0 {
+		return nil, ErrInvalidTreasury
+	}
+	for _, operator := range g.Operators {
+		if _, err := utils.ParseAddress(operator); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOperator, err)
+		}
+	}
 	return g, nil
 }
 
@@ -112,12 +135,21 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, db chain.Databa
 		if err != nil {
 			return err
 		}
-		supply, err = smath.Add64(supply, alloc.Balance)
+		supply, err = smath.Add64(supply, alloc.Energy)
 		if err != nil {
 			return err
 		}
-		if err := storage.SetBalance(ctx, db, pk, ids.Empty, alloc.Balance); err != nil {
-			return fmt.Errorf("%w: addr=%s, bal=%d", err, alloc.Address, alloc.Balance)
+		if err := storage.SetBalance(ctx, db, pk, ids.Empty, alloc.Energy); err != nil {
+			return fmt.Errorf("%w: addr=%s, bal=%d", err, alloc.Address, alloc.Energy)
+		}
+	}
+	for _, operator := range g.Operators {
+		pk, err := utils.ParseAddress(operator)
+		if err != nil {
+			return err
+		}
+		if err := storage.SetRoles(ctx, db, pk, storage.RoleOperator); err != nil {
+			return fmt.Errorf("%w: operator=%s", err, operator)
 		}
 	}
 	return storage.SetAsset(
@@ -128,5 +160,6 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, db chain.Databa
 		supply,
 		crypto.EmptyPublicKey,
 		false,
+		false,
 	)
 }
@@ -3,6 +3,9 @@ package genesis
 import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bbehrman10/energyavavm/utils"
 )
 
 var _ chain.Rules = (*Rules)(nil)
@@ -71,6 +74,45 @@ func (r *Rules) GetWindowTargetBlocks() uint64 {
 	return r.g.WindowTargetBlocks
 }
 
-func (*Rules) FetchCustom(string) (any, bool) {
-	return nil, false
+func (r *Rules) GetMakerFeeBips() uint64 {
+	return r.g.MakerFeeBips
+}
+
+func (r *Rules) GetTakerFeeBips() uint64 {
+	return r.g.TakerFeeBips
+}
+
+// GetTreasury returns the account that collects trading fees. If no treasury
+// is set, it returns [crypto.EmptyPublicKey].
+func (r *Rules) GetTreasury() crypto.PublicKey {
+	if len(r.g.Treasury) == 0 {
+		return crypto.EmptyPublicKey
+	}
+	pk, err := utils.ParseAddress(r.g.Treasury)
+	if err != nil {
+		// This is checked in [New]
+		return crypto.EmptyPublicKey
+	}
+	return pk
+}
+
+// IsPermissioned returns whether accounts need roles to issue energy and
+// list orders. This is the case when genesis declares operators.
+func (r *Rules) IsPermissioned() bool {
+	return len(r.g.Operators) > 0
+}
+
+func (r *Rules) FetchCustom(key string) (any, bool) {
+	switch key {
+	case MakerFeeBipsField:
+		return r.GetMakerFeeBips(), true
+	case TakerFeeBipsField:
+		return r.GetTakerFeeBips(), true
+	case TreasuryField:
+		return r.GetTreasury(), true
+	case PermissionedField:
+		return r.IsPermissioned(), true
+	default:
+		return nil, false
+	}
 }
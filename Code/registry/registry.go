@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/consts"
+)
+
+// Setup types
+func init() {
+	consts.ActionRegistry = codec.NewTypeParser[chain.Action, *warp.Message, bool]()
+	consts.AuthRegistry = codec.NewTypeParser[chain.Auth, *warp.Message, bool]()
+
+	errs := &wrappers.Errs{}
+	errs.Add(
+		// When registering new actions, ALWAYS make sure to append at the end.
+		consts.ActionRegistry.Register(&actions.InitializeEnergyAsset{}, actions.UnmarshalCreateAsset, false),
+		consts.ActionRegistry.Register(&actions.ProduceEnergy{}, actions.UnmarshalProduceEnergy, false),
+		consts.ActionRegistry.Register(&actions.ConsumeEnergy{}, actions.UnmarshalConsumeEnergy, false),
+
+		consts.ActionRegistry.Register(&actions.CreateEnergyOrder{}, actions.UnmarshalCreateEnergyOrder, false),
+		consts.ActionRegistry.Register(&actions.FillEnergyOrder{}, actions.UnmarshalFillOrder, false),
+		consts.ActionRegistry.Register(&actions.CloseEnergyOrder{}, actions.UnmarshalCloseOrder, false),
+
+		consts.ActionRegistry.Register(&actions.TransferEnergy{}, actions.UnmarshalTransferEnergy, false),
+
+		consts.ActionRegistry.Register(&actions.ExportEnergy{}, actions.UnmarshalExportEnergy, false),
+		consts.ActionRegistry.Register(&actions.ImportEnergy{}, actions.UnmarshalImportEnergy, true),
+
+		consts.ActionRegistry.Register(&actions.RegisterMeter{}, actions.UnmarshalRegisterMeter, false),
+		consts.ActionRegistry.Register(&actions.ProduceMeteredEnergy{}, actions.UnmarshalProduceMeteredEnergy, false),
+		consts.ActionRegistry.Register(&actions.TransferCertificate{}, actions.UnmarshalTransferCertificate, false),
+		consts.ActionRegistry.Register(&actions.RetireCertificate{}, actions.UnmarshalRetireCertificate, false),
+
+		consts.ActionRegistry.Register(&actions.ReclaimExpiredOrder{}, actions.UnmarshalReclaimExpiredOrder, false),
+		consts.ActionRegistry.Register(&actions.SubmitLimitOrder{}, actions.UnmarshalSubmitLimitOrder, false),
+		consts.ActionRegistry.Register(&actions.FillBestEnergyOrders{}, actions.UnmarshalFillBestEnergyOrders, false),
+		consts.ActionRegistry.Register(&actions.AmendEnergyOrder{}, actions.UnmarshalAmendEnergyOrder, false),
+
+		consts.ActionRegistry.Register(&actions.GrantRole{}, actions.UnmarshalGrantRole, false),
+		consts.ActionRegistry.Register(&actions.RevokeRole{}, actions.UnmarshalRevokeRole, false),
+
+		consts.ActionRegistry.Register(&actions.UpdateAssetOwner{}, actions.UnmarshalUpdateAssetOwner, false),
+		consts.ActionRegistry.Register(&actions.UpdateAssetMetadata{}, actions.UnmarshalUpdateAssetMetadata, false),
+		consts.ActionRegistry.Register(&actions.FreezeAsset{}, actions.UnmarshalFreezeAsset, false),
+		consts.ActionRegistry.Register(&actions.UnfreezeAsset{}, actions.UnmarshalUnfreezeAsset, false),
+		consts.ActionRegistry.Register(&actions.RegisterSymbol{}, actions.UnmarshalRegisterSymbol, false),
+
+		consts.ActionRegistry.Register(&actions.TransferMeter{}, actions.UnmarshalTransferMeter, false),
+
+		// When registering new auth, ALWAYS make sure to append at the end.
+		consts.AuthRegistry.Register(&auth.ED25519{}, auth.UnmarshalED25519, false),
+	)
+	if errs.Errored() {
+		panic(errs.Err)
+	}
+}
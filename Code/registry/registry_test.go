@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/consts"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+func TestActionRegistry(t *testing.T) {
+	require := require.New(t)
+
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(err)
+	pk := priv.PublicKey()
+
+	// The index of each action is part of the wire format, so this table must
+	// only ever be appended to.
+	tests := []struct {
+		index  uint8
+		action chain.Action
+	}{
+		{0, &actions.InitializeEnergyAsset{Metadata: []byte("solar")}},
+		{1, &actions.ProduceEnergy{To: pk, Asset: ids.GenerateTestID(), Value: 10}},
+		{2, &actions.ConsumeEnergy{Asset: ids.GenerateTestID(), Value: 5}},
+		{3, &actions.CreateEnergyOrder{
+			Side:      actions.SideBid,
+			In:        ids.GenerateTestID(),
+			InTick:    1,
+			Out:       ids.GenerateTestID(),
+			OutTick:   2,
+			Supply:    4,
+			Expiry:    1_700_000_000,
+			AllOrNone: true,
+			MinFill:   2,
+		}},
+		{4, &actions.FillEnergyOrder{
+			Order:       ids.GenerateTestID(),
+			Owner:       pk,
+			In:          ids.GenerateTestID(),
+			Out:         ids.GenerateTestID(),
+			Value:       1,
+			FillOrKill:  true,
+			MinReceived: 1,
+			Treasury:    pk,
+		}},
+		{5, &actions.CloseEnergyOrder{Order: ids.GenerateTestID(), Out: ids.GenerateTestID()}},
+		{6, &actions.TransferEnergy{
+			To:    pk,
+			Asset: ids.GenerateTestID(),
+			Value: 3,
+			Memo:  []byte("rent"),
+		}},
+		{7, &actions.ExportEnergy{
+			To:          pk,
+			Asset:       ids.GenerateTestID(),
+			Value:       8,
+			Return:      true,
+			Destination: ids.GenerateTestID(),
+		}},
+		{9, &actions.RegisterMeter{
+			Meter:      pk,
+			Capacity:   5_000,
+			Reading:    120,
+			Technology: []byte("solar"),
+			Region:     []byte("ERCOT"),
+		}},
+		{10, &actions.ProduceMeteredEnergy{
+			To:           pk,
+			Asset:        ids.GenerateTestID(),
+			Meter:        ids.GenerateTestID(),
+			Start:        100,
+			End:          200,
+			Reading:      4_000,
+			Signature:    crypto.Sign([]byte("reading"), priv),
+			Certificates: 2,
+		}},
+		{11, &actions.TransferCertificate{Certificate: ids.GenerateTestID(), To: pk}},
+		{12, &actions.RetireCertificate{
+			Certificate: ids.GenerateTestID(),
+			Beneficiary: []byte("Acme Corp"),
+		}},
+		{13, &actions.ReclaimExpiredOrder{
+			Order: ids.GenerateTestID(),
+			Owner: pk,
+			Out:   ids.GenerateTestID(),
+		}},
+		{14, &actions.SubmitLimitOrder{
+			Side:    actions.SideAsk,
+			In:      ids.GenerateTestID(),
+			InTick:  3,
+			Out:     ids.GenerateTestID(),
+			OutTick: 5,
+			Supply:  10,
+			Matches: []*actions.OrderMatch{
+				{Order: ids.GenerateTestID(), Owner: pk},
+				{Order: ids.GenerateTestID(), Owner: pk},
+			},
+			Treasury: pk,
+		}},
+		{15, &actions.FillBestEnergyOrders{
+			In:       ids.GenerateTestID(),
+			Out:      ids.GenerateTestID(),
+			Quantity: 20,
+			MaxIn:    3_000,
+			Orders:   []*actions.OrderMatch{{Order: ids.GenerateTestID(), Owner: pk}},
+			Treasury: pk,
+		}},
+		{16, &actions.AmendEnergyOrder{
+			Order:   ids.GenerateTestID(),
+			Out:     ids.GenerateTestID(),
+			InTick:  3,
+			OutTick: 2,
+			Supply:  8,
+		}},
+		{17, &actions.GrantRole{To: pk, Role: storage.RoleProducer}},
+		{18, &actions.RevokeRole{From: pk, Role: storage.RoleOperator}},
+		{19, &actions.UpdateAssetOwner{Asset: ids.GenerateTestID(), Owner: pk}},
+		{20, &actions.UpdateAssetMetadata{Asset: ids.GenerateTestID(), Metadata: []byte("wind")}},
+		{21, &actions.FreezeAsset{Asset: ids.GenerateTestID()}},
+		{22, &actions.UnfreezeAsset{Asset: ids.GenerateTestID()}},
+		{23, &actions.RegisterSymbol{Asset: ids.GenerateTestID(), Symbol: "SOLAR"}},
+		{24, &actions.TransferMeter{Meter: ids.GenerateTestID(), To: pk}},
+	}
+	for _, tt := range tests {
+		index, _, _, ok := consts.ActionRegistry.LookupType(tt.action)
+		require.True(ok, "%T is not registered", tt.action)
+		require.Equal(tt.index, index, "%T changed index", tt.action)
+
+		p := codec.NewWriter(hconsts.MaxInt)
+		tt.action.Marshal(p)
+		require.NoError(p.Err())
+
+		unmarshal, _, ok := consts.ActionRegistry.LookupIndex(index)
+		require.True(ok)
+		parsed, err := unmarshal(codec.NewReader(p.Bytes(), hconsts.MaxInt), nil)
+		require.NoError(err)
+		require.Equal(tt.action, parsed)
+	}
+}
+
+func TestImportRegistry(t *testing.T) {
+	require := require.New(t)
+
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(err)
+	wt := &actions.WarpTransfer{
+		To:    priv.PublicKey(),
+		Asset: ids.GenerateTestID(),
+		Value: 12,
+		TxID:  ids.GenerateTestID(),
+	}
+	payload, err := wt.Marshal()
+	require.NoError(err)
+	uwm, err := warp.NewUnsignedMessage(ids.GenerateTestID(), ids.GenerateTestID(), payload)
+	require.NoError(err)
+	wm, err := warp.NewMessage(uwm, &warp.BitSetSignature{})
+	require.NoError(err)
+
+	// Imports carry everything in the warp message, so they must be
+	// registered as requiring one.
+	index, _, requiresWarp, ok := consts.ActionRegistry.LookupType(&actions.ImportEnergy{})
+	require.True(ok)
+	require.Equal(uint8(8), index)
+	require.True(requiresWarp)
+
+	unmarshal, _, ok := consts.ActionRegistry.LookupIndex(index)
+	require.True(ok)
+	parsed, err := unmarshal(codec.NewReader(nil, hconsts.MaxInt), wm)
+	require.NoError(err)
+	require.Equal(uint64(len(payload)), parsed.MaxUnits(nil))
+	_, err = unmarshal(codec.NewReader(nil, hconsts.MaxInt), nil)
+	require.ErrorIs(err, actions.ErrMissingWarpMessage)
+}
+
+func TestAuthRegistry(t *testing.T) {
+	require := require.New(t)
+
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(err)
+	msg := []byte("energy")
+	a := &auth.ED25519{Signer: priv.PublicKey(), Signature: crypto.Sign(msg, priv)}
+
+	index, _, _, ok := consts.AuthRegistry.LookupType(a)
+	require.True(ok)
+	require.Equal(uint8(0), index)
+
+	p := codec.NewWriter(hconsts.MaxInt)
+	a.Marshal(p)
+	require.NoError(p.Err())
+
+	unmarshal, _, ok := consts.AuthRegistry.LookupIndex(index)
+	require.True(ok)
+	parsed, err := unmarshal(codec.NewReader(p.Bytes(), hconsts.MaxInt), nil)
+	require.NoError(err)
+	require.Equal(a, parsed)
+	require.NoError(parsed.AsyncVerify(msg))
+}
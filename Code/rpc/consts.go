@@ -0,0 +1,9 @@
+package rpc
+
+const (
+	JSONRPCEndpoint = "/energyapi"
+
+	ordersToSend = 128
+	maxLevels    = 128
+	maxOwnerPage = 256
+)
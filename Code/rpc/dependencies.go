@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/genesis"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+type Controller interface {
+	Genesis() *genesis.Genesis
+	Tracer() trace.Tracer
+	GetTransaction(context.Context, ids.ID) (bool, int64, bool, uint64, []byte, error)
+	GetAssetFromState(context.Context, ids.ID) (bool, []byte, uint64, crypto.PublicKey, bool, bool, error)
+	GetBalanceFromState(context.Context, crypto.PublicKey, ids.ID) (uint64, error)
+	Orders(pair string, quantity uint64, limit int) *energyledger.Book
+	Depth(pair string, levels int) *energyledger.Depth
+	GetCreditFromState(context.Context, ids.ID, ids.ID) (uint64, error)
+	GetCertificateFromState(
+		context.Context,
+		ids.ID,
+	) (bool, ids.ID, []byte, []byte, int64, int64, crypto.PublicKey, bool, []byte, error)
+	GetRolesFromState(context.Context, crypto.PublicKey) (uint8, error)
+	GetAssetChanges(context.Context, ids.ID) ([]*storage.AssetChange, error)
+	GetOwnerOrders(context.Context, crypto.PublicKey, ids.ID, int) ([]*storage.OpenOrder, error)
+	GetSymbolAssetFromState(context.Context, string) (bool, ids.ID, error)
+	GetAssetSymbolFromState(context.Context, ids.ID) (bool, string, error)
+}
@@ -0,0 +1,10 @@
+package rpc
+
+import "errors"
+
+var (
+	ErrTxNotFound          = errors.New("tx not found")
+	ErrAssetNotFound       = errors.New("asset not found")
+	ErrCertificateNotFound = errors.New("certificate not found")
+	ErrSymbolNotFound      = errors.New("symbol not found")
+)
@@ -0,0 +1,395 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/requester"
+	hrpc "github.com/ava-labs/hypersdk/rpc"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/consts"
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/genesis"
+	_ "github.com/bbehrman10/energyavavm/registry" // ensure registry populated
+)
+
+type JSONRPCClient struct {
+	requester *requester.EndpointRequester
+	hcli      *hrpc.JSONRPCClient // used for fee estimation and tx submission
+
+	chainID ids.ID
+	g       *genesis.Genesis
+}
+
+// NewJSONRPCClient creates a new client object.
+func NewJSONRPCClient(uri string, chainID ids.ID) *JSONRPCClient {
+	uri = strings.TrimSuffix(uri, "/")
+	req := requester.New(uri+JSONRPCEndpoint, consts.Name)
+	return &JSONRPCClient{
+		requester: req,
+		hcli:      hrpc.NewJSONRPCClient(uri),
+		chainID:   chainID,
+	}
+}
+
+func (cli *JSONRPCClient) Genesis(ctx context.Context) (*genesis.Genesis, error) {
+	if cli.g != nil {
+		return cli.g, nil
+	}
+
+	resp := new(GenesisReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"genesis",
+		nil,
+		resp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cli.g = resp.Genesis
+	return resp.Genesis, nil
+}
+
+func (cli *JSONRPCClient) Tx(ctx context.Context, id ids.ID) (bool, bool, int64, []byte, error) {
+	resp := new(TxReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"tx",
+		&TxArgs{TxID: id},
+		resp,
+	)
+	switch {
+	// We use string parsing here because the JSON-RPC library we use may not
+	// allows us to perform errors.Is.
+	case err != nil && strings.Contains(err.Error(), ErrTxNotFound.Error()):
+		return false, false, -1, nil, nil
+	case err != nil:
+		return false, false, -1, nil, err
+	}
+	return true, resp.Success, resp.Timestamp, resp.Output, nil
+}
+
+func (cli *JSONRPCClient) Asset(
+	ctx context.Context,
+	asset ids.ID,
+) (bool, []byte, uint64, string, bool, bool, error) {
+	resp := new(AssetReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"asset",
+		&AssetArgs{
+			Asset: asset,
+		},
+		resp,
+	)
+	switch {
+	// We use string parsing here because the JSON-RPC library we use may not
+	// allows us to perform errors.Is.
+	case err != nil && strings.Contains(err.Error(), ErrAssetNotFound.Error()):
+		return false, nil, 0, "", false, false, nil
+	case err != nil:
+		return false, nil, 0, "", false, false, err
+	}
+	return true, resp.Metadata, resp.Supply, resp.Owner, resp.Warp, resp.Frozen, nil
+}
+
+// AssetMetadata returns the decoded metadata of the energy asset [asset], if
+// it exists.
+func (cli *JSONRPCClient) AssetMetadata(
+	ctx context.Context,
+	asset ids.ID,
+) (bool, *actions.AssetMetadata, error) {
+	resp := new(AssetReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"asset",
+		&AssetArgs{
+			Asset: asset,
+		},
+		resp,
+	)
+	switch {
+	// We use string parsing here because the JSON-RPC library we use may not
+	// allows us to perform errors.Is.
+	case err != nil && strings.Contains(err.Error(), ErrAssetNotFound.Error()):
+		return false, nil, nil
+	case err != nil:
+		return false, nil, err
+	}
+	return true, resp.Info, nil
+}
+
+func (cli *JSONRPCClient) Balance(ctx context.Context, addr string, asset ids.ID) (uint64, error) {
+	resp := new(BalanceReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"balance",
+		&BalanceArgs{
+			Address: addr,
+			Asset:   asset,
+		},
+		resp,
+	)
+	return resp.Amount, err
+}
+
+func (cli *JSONRPCClient) Orders(ctx context.Context, pair string) (*energyledger.Book, error) {
+	return cli.FillableOrders(ctx, pair, 0)
+}
+
+// FillableOrders returns the orders of [pair] that a taker wanting [quantity]
+// of the locked asset can fill.
+func (cli *JSONRPCClient) FillableOrders(
+	ctx context.Context,
+	pair string,
+	quantity uint64,
+) (*energyledger.Book, error) {
+	resp := new(OrdersReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"orders",
+		&OrdersArgs{
+			Pair:     pair,
+			Quantity: quantity,
+		},
+		resp,
+	)
+	return &energyledger.Book{Bids: resp.Bids, Asks: resp.Asks}, err
+}
+
+// Depth returns up to [levels] aggregated price levels from each side of
+// [pair].
+func (cli *JSONRPCClient) Depth(ctx context.Context, pair string, levels int) (*energyledger.Depth, error) {
+	resp := new(DepthReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"depth",
+		&DepthArgs{
+			Pair:   pair,
+			Levels: levels,
+		},
+		resp,
+	)
+	return &energyledger.Depth{Bids: resp.Bids, Asks: resp.Asks}, err
+}
+
+// OrdersByOwner returns up to [limit] open orders of [addr] after [cursor]
+// and the cursor of the next page, which is empty once all orders were
+// returned.
+func (cli *JSONRPCClient) OrdersByOwner(
+	ctx context.Context,
+	addr string,
+	cursor ids.ID,
+	limit int,
+) ([]*OwnerOrder, ids.ID, error) {
+	resp := new(OrdersByOwnerReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"ordersByOwner",
+		&OrdersByOwnerArgs{
+			Address: addr,
+			Cursor:  cursor,
+			Limit:   limit,
+		},
+		resp,
+	)
+	return resp.Orders, resp.Next, err
+}
+
+func (cli *JSONRPCClient) Credit(ctx context.Context, asset ids.ID, destination ids.ID) (uint64, error) {
+	resp := new(CreditReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"credit",
+		&CreditArgs{
+			Asset:       asset,
+			Destination: destination,
+		},
+		resp,
+	)
+	return resp.Amount, err
+}
+
+// Certificate returns the certificate with [certificate] as its ID, if it
+// exists.
+func (cli *JSONRPCClient) Certificate(
+	ctx context.Context,
+	certificate ids.ID,
+) (bool, *CertificateReply, error) {
+	resp := new(CertificateReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"certificate",
+		&CertificateArgs{
+			Certificate: certificate,
+		},
+		resp,
+	)
+	switch {
+	// We use string parsing here because the JSON-RPC library we use may not
+	// allows us to perform errors.Is.
+	case err != nil && strings.Contains(err.Error(), ErrCertificateNotFound.Error()):
+		return false, nil, nil
+	case err != nil:
+		return false, nil, err
+	}
+	return true, resp, nil
+}
+
+// AssetChanges returns the administrative changes made to [asset], oldest
+// first.
+func (cli *JSONRPCClient) AssetChanges(ctx context.Context, asset ids.ID) ([]*AssetChange, error) {
+	resp := new(AssetChangesReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"assetChanges",
+		&AssetChangesArgs{
+			Asset: asset,
+		},
+		resp,
+	)
+	return resp.Changes, err
+}
+
+// Symbol returns the asset [symbol] is registered to, if any.
+func (cli *JSONRPCClient) Symbol(ctx context.Context, symbol string) (bool, ids.ID, error) {
+	resp := new(SymbolReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"symbol",
+		&SymbolArgs{
+			Symbol: symbol,
+		},
+		resp,
+	)
+	switch {
+	// We use string parsing here because the JSON-RPC library we use may not
+	// allows us to perform errors.Is.
+	case err != nil && strings.Contains(err.Error(), ErrSymbolNotFound.Error()):
+		return false, ids.Empty, nil
+	case err != nil:
+		return false, ids.Empty, err
+	}
+	return true, resp.Asset, nil
+}
+
+// AssetSymbol returns the symbol registered to [asset], if any.
+func (cli *JSONRPCClient) AssetSymbol(ctx context.Context, asset ids.ID) (bool, string, error) {
+	resp := new(SymbolReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"symbol",
+		&SymbolArgs{
+			Asset: asset,
+		},
+		resp,
+	)
+	switch {
+	// We use string parsing here because the JSON-RPC library we use may not
+	// allows us to perform errors.Is.
+	case err != nil && strings.Contains(err.Error(), ErrSymbolNotFound.Error()):
+		return false, "", nil
+	case err != nil:
+		return false, "", err
+	}
+	return true, resp.Symbol, nil
+}
+
+// Roles returns the roles held by [addr] as a bitmask of the roles defined in
+// storage.
+func (cli *JSONRPCClient) Roles(ctx context.Context, addr string) (uint8, error) {
+	resp := new(RolesReply)
+	err := cli.requester.SendRequest(
+		ctx,
+		"roles",
+		&RolesArgs{
+			Address: addr,
+		},
+		resp,
+	)
+	return resp.Roles, err
+}
+
+func (cli *JSONRPCClient) WaitForBalance(
+	ctx context.Context,
+	addr string,
+	asset ids.ID,
+	min uint64,
+) error {
+	return hrpc.Wait(ctx, func(ctx context.Context) (bool, error) {
+		balance, err := cli.Balance(ctx, addr, asset)
+		if err != nil {
+			return false, err
+		}
+		return balance >= min, nil
+	})
+}
+
+// WaitForTransaction blocks until [txID] is accepted and returns whether it
+// succeeded along with the output of its action.
+func (cli *JSONRPCClient) WaitForTransaction(ctx context.Context, txID ids.ID) (bool, []byte, error) {
+	var (
+		success bool
+		output  []byte
+	)
+	if err := hrpc.Wait(ctx, func(ctx context.Context) (bool, error) {
+		found, isuccess, _, ioutput, err := cli.Tx(ctx, txID)
+		if err != nil {
+			return false, err
+		}
+		success = isuccess
+		output = ioutput
+		return found, nil
+	}); err != nil {
+		return false, nil, err
+	}
+	return success, output, nil
+}
+
+// GenerateTransaction signs [action] with [factory] and returns a function that
+// submits it, the signed transaction and the max fee it will pay at the
+// current unit price.
+func (cli *JSONRPCClient) GenerateTransaction(
+	ctx context.Context,
+	action chain.Action,
+	factory *auth.ED25519Factory,
+	modifiers ...hrpc.Modifier,
+) (func(context.Context) error, *chain.Transaction, uint64, error) {
+	parser, err := cli.Parser(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return cli.hcli.GenerateTransaction(ctx, parser, nil, action, factory, modifiers...)
+}
+
+var _ chain.Parser = (*Parser)(nil)
+
+type Parser struct {
+	chainID ids.ID
+	genesis *genesis.Genesis
+}
+
+func (p *Parser) ChainID() ids.ID {
+	return p.chainID
+}
+
+func (p *Parser) Rules(t int64) chain.Rules {
+	return p.genesis.Rules(t)
+}
+
+func (*Parser) Registry() (chain.ActionRegistry, chain.AuthRegistry) {
+	return consts.ActionRegistry, consts.AuthRegistry
+}
+
+func (cli *JSONRPCClient) Parser(ctx context.Context) (chain.Parser, error) {
+	g, err := cli.Genesis(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{cli.chainID, g}, nil
+}
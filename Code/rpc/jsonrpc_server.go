@@ -0,0 +1,433 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/genesis"
+	"github.com/bbehrman10/energyavavm/storage"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+type JSONRPCServer struct {
+	c Controller
+}
+
+func NewJSONRPCServer(c Controller) *JSONRPCServer {
+	return &JSONRPCServer{c}
+}
+
+type GenesisReply struct {
+	Genesis *genesis.Genesis `json:"genesis"`
+}
+
+func (j *JSONRPCServer) Genesis(_ *http.Request, _ *struct{}, reply *GenesisReply) (err error) {
+	reply.Genesis = j.c.Genesis()
+	return nil
+}
+
+type TxArgs struct {
+	TxID ids.ID `json:"txId"`
+}
+
+type TxReply struct {
+	Timestamp int64  `json:"timestamp"`
+	Success   bool   `json:"success"`
+	Units     uint64 `json:"units"`
+	Output    []byte `json:"output"`
+}
+
+func (j *JSONRPCServer) Tx(req *http.Request, args *TxArgs, reply *TxReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Tx")
+	defer span.End()
+
+	found, t, success, units, output, err := j.c.GetTransaction(ctx, args.TxID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrTxNotFound
+	}
+	reply.Timestamp = t
+	reply.Success = success
+	reply.Units = units
+	reply.Output = output
+	return nil
+}
+
+type AssetArgs struct {
+	Asset ids.ID `json:"asset"`
+}
+
+type AssetReply struct {
+	Metadata []byte `json:"metadata"`
+	Supply   uint64 `json:"supply"`
+	Owner    string `json:"owner"`
+	Warp     bool   `json:"warp"`
+	Frozen   bool   `json:"frozen"`
+
+	// Info is the decoded [Metadata] of energy assets. It is not set for the
+	// native asset and imported assets.
+	Info *actions.AssetMetadata `json:"info,omitempty"`
+}
+
+func (j *JSONRPCServer) Asset(req *http.Request, args *AssetArgs, reply *AssetReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Asset")
+	defer span.End()
+
+	exists, metadata, supply, owner, warp, frozen, err := j.c.GetAssetFromState(ctx, args.Asset)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrAssetNotFound
+	}
+	reply.Metadata = metadata
+	reply.Supply = supply
+	reply.Owner = utils.Address(owner)
+	reply.Warp = warp
+	reply.Frozen = frozen
+	if args.Asset != ids.Empty && !warp {
+		// Metadata is verified when it is stored
+		info, err := actions.UnmarshalAssetMetadata(metadata)
+		if err != nil {
+			return err
+		}
+		reply.Info = info
+	}
+	return err
+}
+
+type BalanceArgs struct {
+	Address string `json:"address"`
+	Asset   ids.ID `json:"asset"`
+}
+
+type BalanceReply struct {
+	Amount uint64 `json:"amount"`
+}
+
+func (j *JSONRPCServer) Balance(req *http.Request, args *BalanceArgs, reply *BalanceReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Balance")
+	defer span.End()
+
+	addr, err := utils.ParseAddress(args.Address)
+	if err != nil {
+		return err
+	}
+	balance, err := j.c.GetBalanceFromState(ctx, addr, args.Asset)
+	if err != nil {
+		return err
+	}
+	reply.Amount = balance
+	return err
+}
+
+type OrdersArgs struct {
+	// Pair is denoted as <asset 1>-<asset 2>, where each asset is either an
+	// ID or a registered symbol.
+	Pair string `json:"pair"`
+
+	// Quantity filters out orders that can't be filled by a taker wanting
+	// this much of the locked asset. 0 returns all orders.
+	Quantity uint64 `json:"quantity"`
+}
+
+type OrdersReply struct {
+	Bids []*energyledger.EnergyOrder `json:"bids"`
+	Asks []*energyledger.EnergyOrder `json:"asks"`
+}
+
+func (j *JSONRPCServer) Orders(req *http.Request, args *OrdersArgs, reply *OrdersReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Orders")
+	defer span.End()
+
+	pair, err := actions.ParsePair(args.Pair, j.lookupSymbol(ctx))
+	if err != nil {
+		return err
+	}
+	book := j.c.Orders(pair, args.Quantity, ordersToSend)
+	reply.Bids = book.Bids
+	reply.Asks = book.Asks
+	return nil
+}
+
+type DepthArgs struct {
+	// Pair is denoted the same way as in [OrdersArgs].
+	Pair string `json:"pair"`
+
+	// Levels is the number of price levels returned from each side, up to
+	// [maxLevels]. 0 returns [maxLevels].
+	Levels int `json:"levels"`
+}
+
+type DepthReply struct {
+	Bids []*energyledger.Level `json:"bids"`
+	Asks []*energyledger.Level `json:"asks"`
+}
+
+func (j *JSONRPCServer) Depth(req *http.Request, args *DepthArgs, reply *DepthReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Depth")
+	defer span.End()
+
+	pair, err := actions.ParsePair(args.Pair, j.lookupSymbol(ctx))
+	if err != nil {
+		return err
+	}
+	levels := args.Levels
+	if levels <= 0 || levels > maxLevels {
+		levels = maxLevels
+	}
+	depth := j.c.Depth(pair, levels)
+	reply.Bids = depth.Bids
+	reply.Asks = depth.Asks
+	return nil
+}
+
+type OrdersByOwnerArgs struct {
+	Address string `json:"address"`
+
+	// Cursor is the last order of the previous page, or empty for the first.
+	Cursor ids.ID `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type OwnerOrder struct {
+	ID        ids.ID `json:"id"`
+	Side      uint8  `json:"side"`
+	In        ids.ID `json:"in"`
+	InTick    uint64 `json:"inTick"`
+	Out       ids.ID `json:"out"`
+	OutTick   uint64 `json:"outTick"`
+	Remaining uint64 `json:"remaining"`
+	Expiry    int64  `json:"expiry"`
+	AllOrNone bool   `json:"allOrNone"`
+	MinFill   uint64 `json:"minFill"`
+}
+
+type OrdersByOwnerReply struct {
+	Orders []*OwnerOrder `json:"orders"`
+
+	// Next is the cursor of the next page, or empty if there are no more
+	// orders.
+	Next ids.ID `json:"next"`
+}
+
+// OrdersByOwner returns the open orders of an address in every pair, whether
+// it is tracked or not.
+func (j *JSONRPCServer) OrdersByOwner(req *http.Request, args *OrdersByOwnerArgs, reply *OrdersByOwnerReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.OrdersByOwner")
+	defer span.End()
+
+	addr, err := utils.ParseAddress(args.Address)
+	if err != nil {
+		return err
+	}
+	limit := args.Limit
+	if limit <= 0 || limit > maxOwnerPage {
+		limit = maxOwnerPage
+	}
+	// Read 1 more order than requested to know if there is another page
+	orders, err := j.c.GetOwnerOrders(ctx, addr, args.Cursor, limit+1)
+	if err != nil {
+		return err
+	}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		reply.Next = orders[limit-1].ID
+	}
+	reply.Orders = NewOwnerOrders(orders)
+	return nil
+}
+
+// NewOwnerOrders converts open orders to their RPC form.
+func NewOwnerOrders(orders []*storage.OpenOrder) []*OwnerOrder {
+	ownerOrders := make([]*OwnerOrder, len(orders))
+	for i, order := range orders {
+		ownerOrders[i] = &OwnerOrder{
+			ID:        order.ID,
+			Side:      order.Side,
+			In:        order.In,
+			InTick:    order.InTick,
+			Out:       order.Out,
+			OutTick:   order.OutTick,
+			Remaining: order.Remaining,
+			Expiry:    order.Expiry,
+			AllOrNone: order.AllOrNone,
+			MinFill:   order.MinFill,
+		}
+	}
+	return ownerOrders
+}
+
+type CreditArgs struct {
+	Destination ids.ID `json:"destination"`
+	Asset       ids.ID `json:"asset"`
+}
+
+type CreditReply struct {
+	Amount uint64 `json:"amount"`
+}
+
+func (j *JSONRPCServer) Credit(req *http.Request, args *CreditArgs, reply *CreditReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Credit")
+	defer span.End()
+
+	amount, err := j.c.GetCreditFromState(ctx, args.Asset, args.Destination)
+	if err != nil {
+		return err
+	}
+	reply.Amount = amount
+	return nil
+}
+
+type CertificateArgs struct {
+	Certificate ids.ID `json:"certificate"`
+}
+
+type CertificateReply struct {
+	Meter       ids.ID `json:"meter"`
+	Technology  []byte `json:"technology"`
+	Region      []byte `json:"region"`
+	Start       int64  `json:"start"`
+	End         int64  `json:"end"`
+	Owner       string `json:"owner"`
+	Retired     bool   `json:"retired"`
+	Beneficiary []byte `json:"beneficiary"`
+}
+
+func (j *JSONRPCServer) Certificate(req *http.Request, args *CertificateArgs, reply *CertificateReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Certificate")
+	defer span.End()
+
+	exists, meter, technology, region, start, end, owner, retired, beneficiary, err := j.c.GetCertificateFromState(
+		ctx,
+		args.Certificate,
+	)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCertificateNotFound
+	}
+	reply.Meter = meter
+	reply.Technology = technology
+	reply.Region = region
+	reply.Start = start
+	reply.End = end
+	reply.Owner = utils.Address(owner)
+	reply.Retired = retired
+	reply.Beneficiary = beneficiary
+	return nil
+}
+
+type RolesArgs struct {
+	Address string `json:"address"`
+}
+
+type RolesReply struct {
+	Roles uint8 `json:"roles"`
+}
+
+func (j *JSONRPCServer) Roles(req *http.Request, args *RolesArgs, reply *RolesReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Roles")
+	defer span.End()
+
+	addr, err := utils.ParseAddress(args.Address)
+	if err != nil {
+		return err
+	}
+	roles, err := j.c.GetRolesFromState(ctx, addr)
+	if err != nil {
+		return err
+	}
+	reply.Roles = roles
+	return nil
+}
+
+type AssetChangesArgs struct {
+	Asset ids.ID `json:"asset"`
+}
+
+type AssetChange struct {
+	TxID      ids.ID `json:"txId"`
+	Timestamp int64  `json:"timestamp"`
+	Kind      uint8  `json:"kind"`
+	Actor     string `json:"actor"`
+	Value     []byte `json:"value"`
+}
+
+type AssetChangesReply struct {
+	Changes []*AssetChange `json:"changes"`
+}
+
+func (j *JSONRPCServer) AssetChanges(req *http.Request, args *AssetChangesArgs, reply *AssetChangesReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.AssetChanges")
+	defer span.End()
+
+	changes, err := j.c.GetAssetChanges(ctx, args.Asset)
+	if err != nil {
+		return err
+	}
+	reply.Changes = make([]*AssetChange, len(changes))
+	for i, change := range changes {
+		reply.Changes[i] = &AssetChange{
+			TxID:      change.TxID,
+			Timestamp: change.Timestamp,
+			Kind:      change.Kind,
+			Actor:     utils.Address(change.Actor),
+			Value:     change.Value,
+		}
+	}
+	return nil
+}
+
+type SymbolArgs struct {
+	// Symbol is looked up if set, otherwise the symbol of Asset is.
+	Symbol string `json:"symbol"`
+	Asset  ids.ID `json:"asset"`
+}
+
+type SymbolReply struct {
+	Symbol string `json:"symbol"`
+	Asset  ids.ID `json:"asset"`
+}
+
+func (j *JSONRPCServer) Symbol(req *http.Request, args *SymbolArgs, reply *SymbolReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Symbol")
+	defer span.End()
+
+	if len(args.Symbol) > 0 {
+		exists, asset, err := j.c.GetSymbolAssetFromState(ctx, args.Symbol)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrSymbolNotFound
+		}
+		reply.Symbol = args.Symbol
+		reply.Asset = asset
+		return nil
+	}
+	exists, symbol, err := j.c.GetAssetSymbolFromState(ctx, args.Asset)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrSymbolNotFound
+	}
+	reply.Symbol = symbol
+	reply.Asset = args.Asset
+	return nil
+}
+
+func (j *JSONRPCServer) lookupSymbol(ctx context.Context) actions.SymbolLookup {
+	return func(symbol string) (ids.ID, bool, error) {
+		exists, asset, err := j.c.GetSymbolAssetFromState(ctx, symbol)
+		return asset, exists, err
+	}
+}
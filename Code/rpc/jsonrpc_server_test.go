@@ -0,0 +1,327 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/storage"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+// testController records the arguments the server passes on, and panics on
+// any method a test doesn't expect to be called.
+type testController struct {
+	Controller
+
+	tracer   trace.Tracer
+	txs      map[ids.ID]*TxReply
+	assets   map[ids.ID]*testAsset
+	balances map[ids.ID]uint64
+	symbols  map[string]ids.ID
+	orders   []*storage.OpenOrder
+
+	balanceOwner crypto.PublicKey
+	ordersPair   string
+	ordersQty    uint64
+	ordersLimit  int
+	depthPair    string
+	depthLevels  int
+	ownerLimit   int
+}
+
+func newTestController(t *testing.T) *testController {
+	tracer, err := trace.New(trace.Config{})
+	require.NoError(t, err)
+	return &testController{
+		tracer:   tracer,
+		txs:      map[ids.ID]*TxReply{},
+		assets:   map[ids.ID]*testAsset{},
+		balances: map[ids.ID]uint64{},
+		symbols:  map[string]ids.ID{},
+	}
+}
+
+type testAsset struct {
+	metadata []byte
+	supply   uint64
+	owner    crypto.PublicKey
+	warp     bool
+}
+
+func (c *testController) Tracer() trace.Tracer {
+	return c.tracer
+}
+
+func (c *testController) GetTransaction(_ context.Context, txID ids.ID) (bool, int64, bool, uint64, []byte, error) {
+	tx, ok := c.txs[txID]
+	if !ok {
+		return false, 0, false, 0, nil, nil
+	}
+	return true, tx.Timestamp, tx.Success, tx.Units, tx.Output, nil
+}
+
+func (c *testController) GetAssetFromState(
+	_ context.Context,
+	asset ids.ID,
+) (bool, []byte, uint64, crypto.PublicKey, bool, bool, error) {
+	a, ok := c.assets[asset]
+	if !ok {
+		return false, nil, 0, crypto.EmptyPublicKey, false, false, nil
+	}
+	return true, a.metadata, a.supply, a.owner, a.warp, false, nil
+}
+
+func (c *testController) GetBalanceFromState(_ context.Context, owner crypto.PublicKey, asset ids.ID) (uint64, error) {
+	c.balanceOwner = owner
+	return c.balances[asset], nil
+}
+
+func (c *testController) Orders(pair string, quantity uint64, limit int) *energyledger.Book {
+	c.ordersPair = pair
+	c.ordersQty = quantity
+	c.ordersLimit = limit
+	return &energyledger.Book{}
+}
+
+func (c *testController) Depth(pair string, levels int) *energyledger.Depth {
+	c.depthPair = pair
+	c.depthLevels = levels
+	return &energyledger.Depth{}
+}
+
+func (c *testController) GetOwnerOrders(
+	_ context.Context,
+	_ crypto.PublicKey,
+	_ ids.ID,
+	limit int,
+) ([]*storage.OpenOrder, error) {
+	c.ownerLimit = limit
+	if len(c.orders) > limit {
+		return c.orders[:limit], nil
+	}
+	return c.orders, nil
+}
+
+func (c *testController) GetSymbolAssetFromState(_ context.Context, symbol string) (bool, ids.ID, error) {
+	asset, ok := c.symbols[symbol]
+	return ok, asset, nil
+}
+
+func TestTx(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestController(t)
+	txID := ids.GenerateTestID()
+	c.txs[txID] = &TxReply{Timestamp: 10, Success: true, Units: 3, Output: []byte("ok")}
+	j := NewJSONRPCServer(c)
+	req := httptest.NewRequest("POST", "/", nil)
+
+	// Malformed IDs are rejected when the request is decoded
+	require.Error(json.Unmarshal([]byte(`{"txId":"bad"}`), new(TxArgs)))
+
+	var reply TxReply
+	require.ErrorIs(j.Tx(req, &TxArgs{TxID: ids.GenerateTestID()}, &reply), ErrTxNotFound)
+	require.NoError(j.Tx(req, &TxArgs{TxID: txID}, &reply))
+	require.Equal(*c.txs[txID], reply)
+}
+
+func TestAsset(t *testing.T) {
+	require := require.New(t)
+
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(err)
+	owner := priv.PublicKey()
+	metadata, err := (&actions.AssetMetadata{
+		Symbol: "SOLAR",
+		Unit:   actions.UnitKWh,
+		Source: "solar",
+	}).Marshal()
+	require.NoError(err)
+
+	c := newTestController(t)
+	asset, imported := ids.GenerateTestID(), ids.GenerateTestID()
+	c.assets[ids.Empty] = &testAsset{metadata: []byte("ETKN"), supply: 100}
+	c.assets[asset] = &testAsset{metadata: metadata, supply: 10, owner: owner}
+	c.assets[imported] = &testAsset{metadata: []byte("remote"), supply: 5, warp: true}
+	j := NewJSONRPCServer(c)
+	req := httptest.NewRequest("POST", "/", nil)
+
+	// Malformed IDs are rejected when the request is decoded
+	require.Error(json.Unmarshal([]byte(`{"asset":"bad"}`), new(AssetArgs)))
+
+	var reply AssetReply
+	require.ErrorIs(j.Asset(req, &AssetArgs{Asset: ids.GenerateTestID()}, &reply), ErrAssetNotFound)
+
+	// Only energy assets have their metadata decoded
+	reply = AssetReply{}
+	require.NoError(j.Asset(req, &AssetArgs{Asset: asset}, &reply))
+	require.Equal(metadata, reply.Metadata)
+	require.Equal(uint64(10), reply.Supply)
+	require.Equal(utils.Address(owner), reply.Owner)
+	require.NotNil(reply.Info)
+	require.Equal("SOLAR", reply.Info.Symbol)
+
+	reply = AssetReply{}
+	require.NoError(j.Asset(req, &AssetArgs{Asset: ids.Empty}, &reply))
+	require.Equal(uint64(100), reply.Supply)
+	require.Nil(reply.Info)
+
+	reply = AssetReply{}
+	require.NoError(j.Asset(req, &AssetArgs{Asset: imported}, &reply))
+	require.True(reply.Warp)
+	require.Nil(reply.Info)
+}
+
+func TestBalance(t *testing.T) {
+	require := require.New(t)
+
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(err)
+	addr := utils.Address(priv.PublicKey())
+
+	c := newTestController(t)
+	asset := ids.GenerateTestID()
+	c.balances[asset] = 42
+	j := NewJSONRPCServer(c)
+	req := httptest.NewRequest("POST", "/", nil)
+
+	// Malformed IDs are rejected when the request is decoded
+	require.Error(json.Unmarshal([]byte(`{"address":"","asset":"bad"}`), new(BalanceArgs)))
+
+	// Invalid addresses are rejected before the controller is asked
+	var reply BalanceReply
+	require.Error(j.Balance(req, &BalanceArgs{Address: "bad", Asset: asset}, &reply))
+	require.Error(j.Balance(req, &BalanceArgs{Asset: asset}, &reply))
+	require.Equal(crypto.EmptyPublicKey, c.balanceOwner)
+
+	require.NoError(j.Balance(req, &BalanceArgs{Address: addr, Asset: asset}, &reply))
+	require.Equal(priv.PublicKey(), c.balanceOwner)
+	require.Equal(uint64(42), reply.Amount)
+	require.NoError(j.Balance(req, &BalanceArgs{Address: addr, Asset: ids.GenerateTestID()}, &reply))
+	require.Zero(reply.Amount)
+}
+
+func TestOrders(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestController(t)
+	asset := ids.GenerateTestID()
+	c.symbols["SOLAR"] = asset
+	j := NewJSONRPCServer(c)
+	req := httptest.NewRequest("POST", "/", nil)
+
+	// Invalid and unknown pairs are rejected before the controller is asked
+	var reply OrdersReply
+	require.ErrorIs(j.Orders(req, &OrdersArgs{}, &reply), actions.ErrInvalidPair)
+	require.ErrorIs(j.Orders(req, &OrdersArgs{Pair: "SOLAR"}, &reply), actions.ErrInvalidPair)
+	require.ErrorIs(j.Orders(req, &OrdersArgs{Pair: "SOLAR-WIND"}, &reply), actions.ErrSymbolMissing)
+	require.ErrorIs(j.Orders(req, &OrdersArgs{Pair: "ETKN-solar"}, &reply), actions.ErrInvalidSymbol)
+	require.Empty(c.ordersPair)
+
+	// Symbols and IDs name the same pair
+	require.NoError(j.Orders(req, &OrdersArgs{Pair: "SOLAR-ETKN", Quantity: 7}, &reply))
+	require.Equal(actions.PairID(asset, ids.Empty), c.ordersPair)
+	require.Equal(uint64(7), c.ordersQty)
+	require.Equal(ordersToSend, c.ordersLimit)
+
+	c.ordersPair = ""
+	require.NoError(j.Orders(req, &OrdersArgs{Pair: asset.String() + "-ETKN"}, &reply))
+	require.Equal(actions.PairID(asset, ids.Empty), c.ordersPair)
+}
+
+func TestDepth(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestController(t)
+	asset := ids.GenerateTestID()
+	c.symbols["SOLAR"] = asset
+	j := NewJSONRPCServer(c)
+	req := httptest.NewRequest("POST", "/", nil)
+
+	// Invalid pairs are rejected before the controller is asked
+	var reply DepthReply
+	err := j.Depth(req, &DepthArgs{Pair: "SOLAR"}, &reply)
+	require.ErrorIs(err, actions.ErrInvalidPair)
+	err = j.Depth(req, &DepthArgs{Pair: "SOLAR-WIND"}, &reply)
+	require.ErrorIs(err, actions.ErrSymbolMissing)
+	err = j.Depth(req, &DepthArgs{Pair: "SOLAR-solar"}, &reply)
+	require.ErrorIs(err, actions.ErrInvalidSymbol)
+	require.Zero(c.depthLevels)
+
+	tests := []struct {
+		levels   int
+		expected int
+	}{
+		{0, maxLevels},
+		{-1, maxLevels},
+		{maxLevels + 1, maxLevels},
+		{maxLevels, maxLevels},
+		{5, 5},
+	}
+	for _, tt := range tests {
+		err := j.Depth(req, &DepthArgs{Pair: "SOLAR-ETKN", Levels: tt.levels}, &reply)
+		require.NoError(err)
+		require.Equal(actions.PairID(asset, ids.Empty), c.depthPair)
+		require.Equal(tt.expected, c.depthLevels, "levels %d", tt.levels)
+	}
+}
+
+func TestOrdersByOwner(t *testing.T) {
+	require := require.New(t)
+
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(err)
+	addr := utils.Address(priv.PublicKey())
+
+	c := newTestController(t)
+	for i := 0; i < 3; i++ {
+		c.orders = append(c.orders, &storage.OpenOrder{ID: ids.GenerateTestID(), Remaining: uint64(i + 1)})
+	}
+	j := NewJSONRPCServer(c)
+	req := httptest.NewRequest("POST", "/", nil)
+
+	// Invalid addresses are rejected before the controller is asked
+	var reply OrdersByOwnerReply
+	require.Error(j.OrdersByOwner(req, &OrdersByOwnerArgs{Address: "bad"}, &reply))
+	require.Error(j.OrdersByOwner(req, &OrdersByOwnerArgs{}, &reply))
+	require.Zero(c.ownerLimit)
+
+	// Out of range limits are clamped, and 1 more order is read to find the
+	// next page
+	for _, limit := range []int{0, -1, maxOwnerPage + 1} {
+		reply = OrdersByOwnerReply{}
+		err := j.OrdersByOwner(req, &OrdersByOwnerArgs{Address: addr, Limit: limit}, &reply)
+		require.NoError(err)
+		require.Equal(maxOwnerPage+1, c.ownerLimit, "limit %d", limit)
+		require.Len(reply.Orders, 3)
+		require.Equal(ids.Empty, reply.Next)
+	}
+
+	// A full page returns the cursor of its last order
+	reply = OrdersByOwnerReply{}
+	err = j.OrdersByOwner(req, &OrdersByOwnerArgs{Address: addr, Limit: 2}, &reply)
+	require.NoError(err)
+	require.Equal(3, c.ownerLimit)
+	require.Len(reply.Orders, 2)
+	require.Equal(c.orders[0].ID, reply.Orders[0].ID)
+	require.Equal(c.orders[1].ID, reply.Orders[1].ID)
+	require.Equal(uint64(2), reply.Orders[1].Remaining)
+	require.Equal(c.orders[1].ID, reply.Next)
+
+	// The last page has no cursor
+	reply = OrdersByOwnerReply{}
+	err = j.OrdersByOwner(req, &OrdersByOwnerArgs{Address: addr, Limit: 3}, &reply)
+	require.NoError(err)
+	require.Equal(4, c.ownerLimit)
+	require.Len(reply.Orders, 3)
+	require.Equal(ids.Empty, reply.Next)
+}
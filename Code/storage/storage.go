@@ -19,8 +19,15 @@ import (
 type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 
 const (
-	txPrefix = 0x0
+	// metaDB prefixes
+	txPrefix          = 0x0
+	assetChangePrefix = 0x1
+	openOrderPrefix   = 0x2
+	symbolCopyPrefix  = 0x3
+	ownerOrderPrefix  = 0x4
+	orderIndexPrefix  = 0x5
 
+	// state prefixes
 	balancePrefix      = 0x1
 	assetPrefix        = 0x2
 	energyOrderPrefix  = 0x3
@@ -28,6 +35,31 @@ const (
 	heightPrefix       = 0x5
 	incomingWarpPrefix = 0x6
 	outgoingWarpPrefix = 0x7
+	meterPrefix        = 0x8
+	certificatePrefix  = 0x9
+	rolePrefix         = 0xa
+	symbolPrefix       = 0xb
+	assetSymbolPrefix  = 0xc
+)
+
+// Kinds of administrative changes to an asset.
+const (
+	AssetChangeOwner uint8 = iota
+	AssetChangeMetadata
+	AssetChangeFreeze
+	AssetChangeUnfreeze
+	AssetChangeSymbol
+)
+
+// Roles an account can hold. The roles of an account are stored together as
+// a bitmask.
+const (
+	RoleOperator uint8 = 1 << iota
+	RoleProducer
+	RoleRetailer
+	RoleConsumer
+
+	AllRoles = RoleOperator | RoleProducer | RoleRetailer | RoleConsumer
 )
 
 var (
@@ -35,6 +67,8 @@ var (
 	successByte = byte(0x1)
 	heightKey   = []byte{heightPrefix}
 
+	orderIndexKey = []byte{orderIndexPrefix}
+
 	balancePrefixPool = sync.Pool{
 		New: func() any {
 			return make([]byte, 1+crypto.PublicKeyLen+consts.IDLen)
@@ -56,9 +90,10 @@ func StoreTransaction(
 	t int64,
 	success bool,
 	units uint64,
+	output []byte,
 ) error {
 	k := PrefixTxKey(id)
-	v := make([]byte, consts.Uint64Len+1+consts.Uint64Len)
+	v := make([]byte, consts.Uint64Len+1+consts.Uint64Len+len(output))
 	binary.BigEndian.PutUint64(v, uint64(t))
 	if success {
 		v[consts.Uint64Len] = successByte
@@ -66,6 +101,7 @@ func StoreTransaction(
 		v[consts.Uint64Len] = failureByte
 	}
 	binary.BigEndian.PutUint64((v[consts.Uint64Len+1:]), units)
+	copy(v[consts.Uint64Len*2+1:], output)
 	return db.Put(k, v)
 }
 
@@ -73,14 +109,14 @@ func GetTransaction(
 	_ context.Context,
 	db database.KeyValueReader,
 	id ids.ID,
-) (bool, int64, bool, uint64, error) {
+) (bool, int64, bool, uint64, []byte, error) {
 	k := PrefixTxKey(id)
 	v, err := db.Get(k)
 	if errors.Is(err, database.ErrNotFound) {
-		return false, 0, false, 0, nil
+		return false, 0, false, 0, nil, nil
 	}
 	if err != nil {
-		return false, 0, false, 0, err
+		return false, 0, false, 0, nil, err
 	}
 	t := int64(binary.BigEndian.Uint64(v))
 	success := true
@@ -88,7 +124,253 @@ func GetTransaction(
 		success = false
 	}
 	units := binary.BigEndian.Uint64(v[consts.Uint64Len+1:])
-	return true, t, success, units, nil
+	output := v[consts.Uint64Len*2+1:]
+	return true, t, success, units, output, nil
+}
+
+// AssetChange is the audit record of an administrative change to an asset.
+// [Value] is the new owner or metadata and is empty for freezes.
+type AssetChange struct {
+	TxID      ids.ID
+	Timestamp int64
+	Kind      uint8
+	Actor     crypto.PublicKey
+	Value     []byte
+}
+
+// PrefixAssetChangeKey orders the changes to [asset] by the [height] of the
+// block and the [index] of the tx in it.
+func PrefixAssetChangeKey(asset ids.ID, height uint64, index uint32) (k []byte) {
+	k = make([]byte, 1+consts.IDLen+consts.Uint64Len+consts.IntLen)
+	k[0] = assetChangePrefix
+	copy(k[1:], asset[:])
+	binary.BigEndian.PutUint64(k[1+consts.IDLen:], height)
+	binary.BigEndian.PutUint32(k[1+consts.IDLen+consts.Uint64Len:], index)
+	return
+}
+
+// StoreAssetChange records that [actor] changed [asset] in [txID], the
+// [index]th tx of the block at [height] accepted at [t].
+func StoreAssetChange(
+	_ context.Context,
+	db database.KeyValueWriter,
+	asset ids.ID,
+	height uint64,
+	index uint32,
+	txID ids.ID,
+	t int64,
+	kind uint8,
+	actor crypto.PublicKey,
+	value []byte,
+) error {
+	k := PrefixAssetChangeKey(asset, height, index)
+	v := make([]byte, consts.IDLen+consts.Uint64Len+1+crypto.PublicKeyLen+len(value))
+	copy(v, txID[:])
+	binary.BigEndian.PutUint64(v[consts.IDLen:], uint64(t))
+	v[consts.IDLen+consts.Uint64Len] = kind
+	copy(v[consts.IDLen+consts.Uint64Len+1:], actor[:])
+	copy(v[consts.IDLen+consts.Uint64Len+1+crypto.PublicKeyLen:], value)
+	return db.Put(k, v)
+}
+
+// GetAssetChanges returns the administrative changes to [asset], oldest
+// first.
+func GetAssetChanges(
+	_ context.Context,
+	db database.Iteratee,
+	asset ids.ID,
+) ([]*AssetChange, error) {
+	prefix := make([]byte, 1+consts.IDLen)
+	prefix[0] = assetChangePrefix
+	copy(prefix[1:], asset[:])
+	iter := db.NewIteratorWithPrefix(prefix)
+	defer iter.Release()
+
+	changes := []*AssetChange{}
+	for iter.Next() {
+		v := iter.Value()
+		offset := consts.IDLen + consts.Uint64Len + 1 + crypto.PublicKeyLen
+		change := &AssetChange{
+			Timestamp: int64(binary.BigEndian.Uint64(v[consts.IDLen:])),
+			Kind:      v[consts.IDLen+consts.Uint64Len],
+			Value:     make([]byte, len(v)-offset),
+		}
+		copy(change.TxID[:], v)
+		copy(change.Actor[:], v[consts.IDLen+consts.Uint64Len+1:])
+		copy(change.Value, v[offset:])
+		changes = append(changes, change)
+	}
+	return changes, iter.Error()
+}
+
+// OpenOrder is the copy of an order that is still listed. It is kept in the
+// metaDB so the energy ledger can be rebuilt on restart without reading
+// state.
+type OpenOrder struct {
+	ID        ids.ID
+	Side      uint8
+	In        ids.ID
+	InTick    uint64
+	Out       ids.ID
+	OutTick   uint64
+	Remaining uint64
+	Owner     crypto.PublicKey
+	Expiry    int64
+	AllOrNone bool
+	MinFill   uint64
+
+	// Height and Index are the block and tx the order was listed or last
+	// repriced or grown in, which decide its time priority.
+	Height uint64
+	Index  uint32
+}
+
+func PrefixOpenOrderKey(order ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen)
+	k[0] = openOrderPrefix
+	copy(k[1:], order[:])
+	return
+}
+
+func StoreOpenOrder(_ context.Context, db database.KeyValueWriter, order *OpenOrder) error {
+	k := PrefixOpenOrderKey(order.ID)
+	v := encodeEnergyOrder(
+		order.Side,
+		order.In,
+		order.InTick,
+		order.Out,
+		order.OutTick,
+		order.Remaining,
+		order.Owner,
+		order.Expiry,
+		order.AllOrNone,
+		order.MinFill,
+	)
+	v = binary.BigEndian.AppendUint64(v, order.Height)
+	v = binary.BigEndian.AppendUint32(v, order.Index)
+	return db.Put(k, v)
+}
+
+func DeleteOpenOrder(_ context.Context, db database.KeyValueDeleter, order ids.ID) error {
+	return db.Delete(PrefixOpenOrderKey(order))
+}
+
+// GetOpenOrder returns the open order [order], or nil if it is not open.
+func GetOpenOrder(_ context.Context, db database.KeyValueReader, order ids.ID) (*OpenOrder, error) {
+	v, err := db.Get(PrefixOpenOrderKey(order))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeOpenOrder(order, v), nil
+}
+
+// GetOpenOrders returns every open order.
+func GetOpenOrders(_ context.Context, db database.Iteratee) ([]*OpenOrder, error) {
+	iter := db.NewIteratorWithPrefix([]byte{openOrderPrefix})
+	defer iter.Release()
+
+	orders := []*OpenOrder{}
+	for iter.Next() {
+		var id ids.ID
+		copy(id[:], iter.Key()[1:])
+		orders = append(orders, decodeOpenOrder(id, iter.Value()))
+	}
+	return orders, iter.Error()
+}
+
+func decodeOpenOrder(id ids.ID, v []byte) *OpenOrder {
+	order := &OpenOrder{ID: id}
+	order.Side, order.In, order.InTick, order.Out, order.OutTick, order.Remaining,
+		order.Owner, order.Expiry, order.AllOrNone, order.MinFill = decodeEnergyOrder(v)
+	offset := 1 + consts.IDLen*2 + consts.Uint64Len*5 + crypto.PublicKeyLen + 1
+	order.Height = binary.BigEndian.Uint64(v[offset:])
+	order.Index = binary.BigEndian.Uint32(v[offset+consts.Uint64Len:])
+	return order
+}
+
+func PrefixOwnerOrderKey(owner crypto.PublicKey, order ids.ID) (k []byte) {
+	k = make([]byte, 1+crypto.PublicKeyLen+consts.IDLen)
+	k[0] = ownerOrderPrefix
+	copy(k[1:], owner[:])
+	copy(k[1+crypto.PublicKeyLen:], order[:])
+	return
+}
+
+// StoreOwnerOrder indexes [order] under [owner]. The index holds every open
+// order, whether its pair is tracked or not.
+func StoreOwnerOrder(_ context.Context, db database.KeyValueWriter, owner crypto.PublicKey, order ids.ID) error {
+	return db.Put(PrefixOwnerOrderKey(owner, order), nil)
+}
+
+func DeleteOwnerOrder(_ context.Context, db database.KeyValueDeleter, owner crypto.PublicKey, order ids.ID) error {
+	return db.Delete(PrefixOwnerOrderKey(owner, order))
+}
+
+// GetOwnerOrders returns up to [limit] IDs of the open orders of [owner] that
+// come after [cursor], ordered by ID. The first page starts at [ids.Empty].
+func GetOwnerOrders(
+	_ context.Context,
+	db database.Iteratee,
+	owner crypto.PublicKey,
+	cursor ids.ID,
+	limit int,
+) ([]ids.ID, error) {
+	prefix := make([]byte, 1+crypto.PublicKeyLen)
+	prefix[0] = ownerOrderPrefix
+	copy(prefix[1:], owner[:])
+	iter := db.NewIteratorWithStartAndPrefix(PrefixOwnerOrderKey(owner, cursor), prefix)
+	defer iter.Release()
+
+	orders := []ids.ID{}
+	for len(orders) < limit && iter.Next() {
+		var id ids.ID
+		copy(id[:], iter.Key()[1+crypto.PublicKeyLen:])
+		if id == cursor {
+			continue
+		}
+		orders = append(orders, id)
+	}
+	return orders, iter.Error()
+}
+
+// StoreOrderIndexHeight records that the open orders and the index of them
+// by owner are up to date with the block at [height].
+func StoreOrderIndexHeight(_ context.Context, db database.KeyValueWriter, height uint64) error {
+	return db.Put(orderIndexKey, binary.BigEndian.AppendUint64(nil, height))
+}
+
+// GetOrderIndexHeight returns the height recorded by [StoreOrderIndexHeight],
+// if any.
+func GetOrderIndexHeight(_ context.Context, db database.KeyValueReader) (bool, uint64, error) {
+	v, err := db.Get(orderIndexKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return true, binary.BigEndian.Uint64(v), nil
+}
+
+func PrefixSymbolCopyKey(symbol string) (k []byte) {
+	k = make([]byte, 1+len(symbol))
+	k[0] = symbolCopyPrefix
+	copy(k[1:], symbol)
+	return
+}
+
+// StoreSymbolCopy keeps a copy of the registration of [symbol] so it can be
+// resolved before state can be read.
+func StoreSymbolCopy(_ context.Context, db database.KeyValueWriter, symbol string, asset ids.ID) error {
+	return db.Put(PrefixSymbolCopyKey(symbol), asset[:])
+}
+
+func GetSymbolCopy(_ context.Context, db database.KeyValueReader, symbol string) (bool, ids.ID, error) {
+	v, err := db.Get(PrefixSymbolCopyKey(symbol))
+	return innerGetSymbolAsset(v, err)
 }
 
 func PrefixBalanceKey(pk crypto.PublicKey, asset ids.ID) (k []byte) {
@@ -241,7 +523,7 @@ func GetAssetFromState(
 	ctx context.Context,
 	f ReadState,
 	asset ids.ID,
-) (bool, []byte, uint64, crypto.PublicKey, bool, error) {
+) (bool, []byte, uint64, crypto.PublicKey, bool, bool, error) {
 	values, errs := f(ctx, [][]byte{PrefixAssetKey(asset)})
 	return innerGetAsset(values[0], errs[0])
 }
@@ -250,7 +532,7 @@ func GetAsset(
 	ctx context.Context,
 	db chain.Database,
 	asset ids.ID,
-) (bool, []byte, uint64, crypto.PublicKey, bool, error) {
+) (bool, []byte, uint64, crypto.PublicKey, bool, bool, error) {
 	k := PrefixAssetKey(asset)
 	return innerGetAsset(db.GetValue(ctx, k))
 }
@@ -258,12 +540,12 @@ func GetAsset(
 func innerGetAsset(
 	v []byte,
 	err error,
-) (bool, []byte, uint64, crypto.PublicKey, bool, error) {
+) (bool, []byte, uint64, crypto.PublicKey, bool, bool, error) {
 	if errors.Is(err, database.ErrNotFound) {
-		return false, nil, 0, crypto.EmptyPublicKey, false, nil
+		return false, nil, 0, crypto.EmptyPublicKey, false, false, nil
 	}
 	if err != nil {
-		return false, nil, 0, crypto.EmptyPublicKey, false, err
+		return false, nil, 0, crypto.EmptyPublicKey, false, false, err
 	}
 	metadataLen := binary.BigEndian.Uint16(v)
 	metadata := v[consts.Uint16Len : consts.Uint16Len+metadataLen]
@@ -271,9 +553,12 @@ func innerGetAsset(
 	var pk crypto.PublicKey
 	copy(pk[:], v[consts.Uint16Len+metadataLen+consts.Uint64Len:])
 	warp := v[consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen] == 0x1
-	return true, metadata, supply, pk, warp, nil
+	frozen := v[consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen+1] == 0x1
+	return true, metadata, supply, pk, warp, frozen, nil
 }
 
+// SetAsset stores the [metadata], [supply] and [owner] of [asset]. While
+// [frozen] is set, the asset can't be produced, transferred or traded.
 func SetAsset(
 	ctx context.Context,
 	db chain.Database,
@@ -282,19 +567,21 @@ func SetAsset(
 	supply uint64,
 	owner crypto.PublicKey,
 	warp bool,
+	frozen bool,
 ) error {
 	k := PrefixAssetKey(asset)
 	metadataLen := len(metadata)
-	v := make([]byte, consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen+1)
+	v := make([]byte, consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen+2)
 	binary.BigEndian.PutUint16(v, uint16(metadataLen))
 	copy(v[consts.Uint16Len:], metadata)
 	binary.BigEndian.PutUint64(v[consts.Uint16Len+metadataLen:], supply)
 	copy(v[consts.Uint16Len+metadataLen+consts.Uint64Len:], owner[:])
-	b := byte(0x0)
 	if warp {
-		b = 0x1
+		v[consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen] = 0x1
+	}
+	if frozen {
+		v[consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen+1] = 0x1
 	}
-	v[consts.Uint16Len+metadataLen+consts.Uint64Len+crypto.PublicKeyLen] = b
 	return db.Insert(ctx, k, v)
 }
 
@@ -313,56 +600,120 @@ func SetEnergyOrder(
 	ctx context.Context,
 	db chain.Database,
 	tdID ids.ID,
+	side uint8,
 	in ids.ID,
 	inTick uint64,
 	out ids.ID,
 	outTick uint64,
 	supply uint64,
 	owner crypto.PublicKey,
+	expiry int64,
+	allOrNone bool,
+	minFill uint64,
 ) error {
 	k := PrefixEnergyOrderKey(tdID)
-	v := make([]byte, consts.IDLen*2+consts.Uint64Len*3+crypto.PublicKeyLen)
-	copy(v, in[:])
-	binary.BigEndian.PutUint64(v[consts.IDLen:], inTick)
-	copy(v[consts.IDLen+consts.Uint64Len:], out[:])
-	binary.BigEndian.PutUint64(v[consts.IDLen*2+consts.Uint64Len:], outTick)
-	binary.BigEndian.PutUint64(v[consts.IDLen*2+consts.Uint64Len*2:], supply)
-	copy(v[consts.IDLen*2+consts.Uint64Len*3:], owner[:])
+	v := encodeEnergyOrder(side, in, inTick, out, outTick, supply, owner, expiry, allOrNone, minFill)
 	return db.Insert(ctx, k, v)
 }
 
+func encodeEnergyOrder(
+	side uint8,
+	in ids.ID,
+	inTick uint64,
+	out ids.ID,
+	outTick uint64,
+	supply uint64,
+	owner crypto.PublicKey,
+	expiry int64,
+	allOrNone bool,
+	minFill uint64,
+) []byte {
+	v := make([]byte, 1+consts.IDLen*2+consts.Uint64Len*5+crypto.PublicKeyLen+1)
+	v[0] = side
+	copy(v[1:], in[:])
+	binary.BigEndian.PutUint64(v[1+consts.IDLen:], inTick)
+	copy(v[1+consts.IDLen+consts.Uint64Len:], out[:])
+	binary.BigEndian.PutUint64(v[1+consts.IDLen*2+consts.Uint64Len:], outTick)
+	binary.BigEndian.PutUint64(v[1+consts.IDLen*2+consts.Uint64Len*2:], supply)
+	copy(v[1+consts.IDLen*2+consts.Uint64Len*3:], owner[:])
+	binary.BigEndian.PutUint64(v[1+consts.IDLen*2+consts.Uint64Len*3+crypto.PublicKeyLen:], uint64(expiry))
+	if allOrNone {
+		v[1+consts.IDLen*2+consts.Uint64Len*4+crypto.PublicKeyLen] = 1
+	}
+	binary.BigEndian.PutUint64(v[1+consts.IDLen*2+consts.Uint64Len*4+crypto.PublicKeyLen+1:], minFill)
+	return v
+}
+
 func GetEnergyOrder(
 	ctx context.Context,
 	db chain.Database,
 	order ids.ID,
 ) (
 	bool,
+	uint8,
 	ids.ID,
 	uint64,
 	ids.ID,
 	uint64,
 	uint64,
 	crypto.PublicKey,
+	int64,
+	bool,
+	uint64,
 	error,
 ) {
 	k := PrefixEnergyOrderKey(order)
 	v, err := db.GetValue(ctx, k)
 	if errors.Is(err, database.ErrNotFound) {
-		return false, ids.Empty, 0, ids.Empty, 0, 0, crypto.EmptyPublicKey, nil
+		return false, 0, ids.Empty, 0, ids.Empty, 0, 0, crypto.EmptyPublicKey, 0, false, 0, nil
 	}
 	if err != nil {
-		return false, ids.Empty, 0, ids.Empty, 0, 0, crypto.EmptyPublicKey, err
-	}
-	var in ids.ID
-	copy(in[:], v[:consts.IDLen])
-	inTick := binary.BigEndian.Uint64(v[consts.IDLen:])
-	var out ids.ID
-	copy(out[:], v[consts.IDLen+consts.Uint64Len:consts.IDLen*2+consts.Uint64Len])
-	outTick := binary.BigEndian.Uint64(v[consts.IDLen*2+consts.Uint64Len:])
-	supply := binary.BigEndian.Uint64(v[consts.IDLen*2+consts.Uint64Len*2:])
-	var owner crypto.PublicKey
-	copy(owner[:], v[consts.IDLen*2+consts.Uint64Len*3:])
-	return true, in, inTick, out, outTick, supply, owner, nil
+		return false, 0, ids.Empty, 0, ids.Empty, 0, 0, crypto.EmptyPublicKey, 0, false, 0, err
+	}
+	side, in, inTick, out, outTick, supply, owner, expiry, allOrNone, minFill := decodeEnergyOrder(v)
+	return true, side, in, inTick, out, outTick, supply, owner, expiry, allOrNone, minFill, nil
+}
+
+// GetEnergyOrders returns every order in state, ordered by ID. State doesn't
+// record when orders were listed, so [Height] and [Index] are not set.
+func GetEnergyOrders(_ context.Context, db database.Iteratee) ([]*OpenOrder, error) {
+	iter := db.NewIteratorWithPrefix([]byte{energyOrderPrefix})
+	defer iter.Release()
+
+	orders := []*OpenOrder{}
+	for iter.Next() {
+		order := &OpenOrder{}
+		copy(order.ID[:], iter.Key()[1:])
+		order.Side, order.In, order.InTick, order.Out, order.OutTick, order.Remaining,
+			order.Owner, order.Expiry, order.AllOrNone, order.MinFill = decodeEnergyOrder(iter.Value())
+		orders = append(orders, order)
+	}
+	return orders, iter.Error()
+}
+
+func decodeEnergyOrder(v []byte) (
+	side uint8,
+	in ids.ID,
+	inTick uint64,
+	out ids.ID,
+	outTick uint64,
+	supply uint64,
+	owner crypto.PublicKey,
+	expiry int64,
+	allOrNone bool,
+	minFill uint64,
+) {
+	side = v[0]
+	copy(in[:], v[1:1+consts.IDLen])
+	inTick = binary.BigEndian.Uint64(v[1+consts.IDLen:])
+	copy(out[:], v[1+consts.IDLen+consts.Uint64Len:1+consts.IDLen*2+consts.Uint64Len])
+	outTick = binary.BigEndian.Uint64(v[1+consts.IDLen*2+consts.Uint64Len:])
+	supply = binary.BigEndian.Uint64(v[1+consts.IDLen*2+consts.Uint64Len*2:])
+	copy(owner[:], v[1+consts.IDLen*2+consts.Uint64Len*3:])
+	expiry = int64(binary.BigEndian.Uint64(v[1+consts.IDLen*2+consts.Uint64Len*3+crypto.PublicKeyLen:]))
+	allOrNone = v[1+consts.IDLen*2+consts.Uint64Len*4+crypto.PublicKeyLen] == 1
+	minFill = binary.BigEndian.Uint64(v[1+consts.IDLen*2+consts.Uint64Len*4+crypto.PublicKeyLen+1:])
+	return
 }
 
 func DeleteOrder(ctx context.Context, db chain.Database, order ids.ID) error {
@@ -473,6 +824,288 @@ func SubCredit(
 	return SetCredit(ctx, db, asset, destination, ncredit)
 }
 
+func PrefixMeterKey(meter ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen)
+	k[0] = meterPrefix
+	copy(k[1:], meter[:])
+	return
+}
+
+// SetMeter stores the key a meter signs readings with, its nameplate
+// capacity in watts, the account it produces for, the technology and region
+// of its generator and the end and cumulative Wh of the last reading that
+// was credited.
+func SetMeter(
+	ctx context.Context,
+	db chain.Database,
+	meter ids.ID,
+	pk crypto.PublicKey,
+	capacity uint64,
+	owner crypto.PublicKey,
+	technology []byte,
+	region []byte,
+	lastEnd int64,
+	reading uint64,
+) error {
+	k := PrefixMeterKey(meter)
+	technologyLen := len(technology)
+	regionLen := len(region)
+	v := make([]byte, crypto.PublicKeyLen*2+consts.Uint64Len*3+consts.Uint16Len*2+technologyLen+regionLen)
+	copy(v, pk[:])
+	binary.BigEndian.PutUint64(v[crypto.PublicKeyLen:], capacity)
+	copy(v[crypto.PublicKeyLen+consts.Uint64Len:], owner[:])
+	binary.BigEndian.PutUint64(v[crypto.PublicKeyLen*2+consts.Uint64Len:], uint64(lastEnd))
+	binary.BigEndian.PutUint64(v[crypto.PublicKeyLen*2+consts.Uint64Len*2:], reading)
+	offset := crypto.PublicKeyLen*2 + consts.Uint64Len*3
+	binary.BigEndian.PutUint16(v[offset:], uint16(technologyLen))
+	copy(v[offset+consts.Uint16Len:], technology)
+	offset += consts.Uint16Len + technologyLen
+	binary.BigEndian.PutUint16(v[offset:], uint16(regionLen))
+	copy(v[offset+consts.Uint16Len:], region)
+	return db.Insert(ctx, k, v)
+}
+
+func GetMeter(
+	ctx context.Context,
+	db chain.Database,
+	meter ids.ID,
+) (bool, crypto.PublicKey, uint64, crypto.PublicKey, []byte, []byte, int64, uint64, error) {
+	k := PrefixMeterKey(meter)
+	return innerGetMeter(db.GetValue(ctx, k))
+}
+
+func GetMeterFromState(
+	ctx context.Context,
+	f ReadState,
+	meter ids.ID,
+) (bool, crypto.PublicKey, uint64, crypto.PublicKey, []byte, []byte, int64, uint64, error) {
+	values, errs := f(ctx, [][]byte{PrefixMeterKey(meter)})
+	return innerGetMeter(values[0], errs[0])
+}
+
+func innerGetMeter(
+	v []byte,
+	err error,
+) (bool, crypto.PublicKey, uint64, crypto.PublicKey, []byte, []byte, int64, uint64, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, crypto.EmptyPublicKey, 0, crypto.EmptyPublicKey, nil, nil, 0, 0, nil
+	}
+	if err != nil {
+		return false, crypto.EmptyPublicKey, 0, crypto.EmptyPublicKey, nil, nil, 0, 0, err
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], v[:crypto.PublicKeyLen])
+	capacity := binary.BigEndian.Uint64(v[crypto.PublicKeyLen:])
+	var owner crypto.PublicKey
+	copy(owner[:], v[crypto.PublicKeyLen+consts.Uint64Len:])
+	lastEnd := int64(binary.BigEndian.Uint64(v[crypto.PublicKeyLen*2+consts.Uint64Len:]))
+	reading := binary.BigEndian.Uint64(v[crypto.PublicKeyLen*2+consts.Uint64Len*2:])
+	offset := crypto.PublicKeyLen*2 + consts.Uint64Len*3
+	technologyLen := int(binary.BigEndian.Uint16(v[offset:]))
+	technology := v[offset+consts.Uint16Len : offset+consts.Uint16Len+technologyLen]
+	offset += consts.Uint16Len + technologyLen
+	regionLen := int(binary.BigEndian.Uint16(v[offset:]))
+	region := v[offset+consts.Uint16Len : offset+consts.Uint16Len+regionLen]
+	return true, pk, capacity, owner, technology, region, lastEnd, reading, nil
+}
+
+func PrefixCertificateKey(certificate ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen)
+	k[0] = certificatePrefix
+	copy(k[1:], certificate[:])
+	return
+}
+
+// SetCertificate stores a certificate for 1 MWh produced by [meter] between
+// [start] and [end]. Once [retired] is set, [beneficiary] names who the
+// certificate was retired for.
+func SetCertificate(
+	ctx context.Context,
+	db chain.Database,
+	certificate ids.ID,
+	meter ids.ID,
+	technology []byte,
+	region []byte,
+	start int64,
+	end int64,
+	owner crypto.PublicKey,
+	retired bool,
+	beneficiary []byte,
+) error {
+	k := PrefixCertificateKey(certificate)
+	technologyLen := len(technology)
+	regionLen := len(region)
+	beneficiaryLen := len(beneficiary)
+	v := make(
+		[]byte,
+		consts.IDLen+consts.Uint64Len*2+crypto.PublicKeyLen+1+
+			consts.Uint16Len*3+technologyLen+regionLen+beneficiaryLen,
+	)
+	copy(v, meter[:])
+	binary.BigEndian.PutUint64(v[consts.IDLen:], uint64(start))
+	binary.BigEndian.PutUint64(v[consts.IDLen+consts.Uint64Len:], uint64(end))
+	copy(v[consts.IDLen+consts.Uint64Len*2:], owner[:])
+	offset := consts.IDLen + consts.Uint64Len*2 + crypto.PublicKeyLen
+	if retired {
+		v[offset] = 0x1
+	}
+	offset++
+	for _, field := range [][]byte{technology, region, beneficiary} {
+		binary.BigEndian.PutUint16(v[offset:], uint16(len(field)))
+		copy(v[offset+consts.Uint16Len:], field)
+		offset += consts.Uint16Len + len(field)
+	}
+	return db.Insert(ctx, k, v)
+}
+
+func GetCertificate(
+	ctx context.Context,
+	db chain.Database,
+	certificate ids.ID,
+) (bool, ids.ID, []byte, []byte, int64, int64, crypto.PublicKey, bool, []byte, error) {
+	k := PrefixCertificateKey(certificate)
+	return innerGetCertificate(db.GetValue(ctx, k))
+}
+
+func GetCertificateFromState(
+	ctx context.Context,
+	f ReadState,
+	certificate ids.ID,
+) (bool, ids.ID, []byte, []byte, int64, int64, crypto.PublicKey, bool, []byte, error) {
+	values, errs := f(ctx, [][]byte{PrefixCertificateKey(certificate)})
+	return innerGetCertificate(values[0], errs[0])
+}
+
+func innerGetCertificate(
+	v []byte,
+	err error,
+) (bool, ids.ID, []byte, []byte, int64, int64, crypto.PublicKey, bool, []byte, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, ids.Empty, nil, nil, 0, 0, crypto.EmptyPublicKey, false, nil, nil
+	}
+	if err != nil {
+		return false, ids.Empty, nil, nil, 0, 0, crypto.EmptyPublicKey, false, nil, err
+	}
+	var meter ids.ID
+	copy(meter[:], v[:consts.IDLen])
+	start := int64(binary.BigEndian.Uint64(v[consts.IDLen:]))
+	end := int64(binary.BigEndian.Uint64(v[consts.IDLen+consts.Uint64Len:]))
+	var owner crypto.PublicKey
+	copy(owner[:], v[consts.IDLen+consts.Uint64Len*2:])
+	offset := consts.IDLen + consts.Uint64Len*2 + crypto.PublicKeyLen
+	retired := v[offset] == 0x1
+	offset++
+	fields := make([][]byte, 3)
+	for i := range fields {
+		fieldLen := int(binary.BigEndian.Uint16(v[offset:]))
+		fields[i] = v[offset+consts.Uint16Len : offset+consts.Uint16Len+fieldLen]
+		offset += consts.Uint16Len + fieldLen
+	}
+	return true, meter, fields[0], fields[1], start, end, owner, retired, fields[2], nil
+}
+
+func PrefixRoleKey(pk crypto.PublicKey) (k []byte) {
+	k = make([]byte, 1+crypto.PublicKeyLen)
+	k[0] = rolePrefix
+	copy(k[1:], pk[:])
+	return
+}
+
+// SetRoles stores the [roles] held by [pk]. If [pk] holds no roles, its key
+// is removed.
+func SetRoles(ctx context.Context, db chain.Database, pk crypto.PublicKey, roles uint8) error {
+	k := PrefixRoleKey(pk)
+	if roles == 0 {
+		return db.Remove(ctx, k)
+	}
+	return db.Insert(ctx, k, []byte{roles})
+}
+
+func GetRoles(ctx context.Context, db chain.Database, pk crypto.PublicKey) (uint8, error) {
+	k := PrefixRoleKey(pk)
+	return innerGetRoles(db.GetValue(ctx, k))
+}
+
+func GetRolesFromState(ctx context.Context, f ReadState, pk crypto.PublicKey) (uint8, error) {
+	values, errs := f(ctx, [][]byte{PrefixRoleKey(pk)})
+	return innerGetRoles(values[0], errs[0])
+}
+
+func innerGetRoles(v []byte, err error) (uint8, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return v[0], nil
+}
+
+func PrefixSymbolKey(symbol string) (k []byte) {
+	k = make([]byte, 1+len(symbol))
+	k[0] = symbolPrefix
+	copy(k[1:], symbol)
+	return
+}
+
+func PrefixAssetSymbolKey(asset ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen)
+	k[0] = assetSymbolPrefix
+	copy(k[1:], asset[:])
+	return
+}
+
+// SetSymbol registers [symbol] to [asset]. Both directions are stored so the
+// symbol of an asset can be looked up as well.
+func SetSymbol(ctx context.Context, db chain.Database, symbol string, asset ids.ID) error {
+	if err := db.Insert(ctx, PrefixSymbolKey(symbol), asset[:]); err != nil {
+		return err
+	}
+	return db.Insert(ctx, PrefixAssetSymbolKey(asset), []byte(symbol))
+}
+
+func GetSymbolAsset(ctx context.Context, db chain.Database, symbol string) (bool, ids.ID, error) {
+	k := PrefixSymbolKey(symbol)
+	return innerGetSymbolAsset(db.GetValue(ctx, k))
+}
+
+func GetSymbolAssetFromState(ctx context.Context, f ReadState, symbol string) (bool, ids.ID, error) {
+	values, errs := f(ctx, [][]byte{PrefixSymbolKey(symbol)})
+	return innerGetSymbolAsset(values[0], errs[0])
+}
+
+func innerGetSymbolAsset(v []byte, err error) (bool, ids.ID, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, ids.Empty, nil
+	}
+	if err != nil {
+		return false, ids.Empty, err
+	}
+	var asset ids.ID
+	copy(asset[:], v)
+	return true, asset, nil
+}
+
+func GetAssetSymbol(ctx context.Context, db chain.Database, asset ids.ID) (bool, string, error) {
+	k := PrefixAssetSymbolKey(asset)
+	return innerGetAssetSymbol(db.GetValue(ctx, k))
+}
+
+func GetAssetSymbolFromState(ctx context.Context, f ReadState, asset ids.ID) (bool, string, error) {
+	values, errs := f(ctx, [][]byte{PrefixAssetSymbolKey(asset)})
+	return innerGetAssetSymbol(values[0], errs[0])
+}
+
+func innerGetAssetSymbol(v []byte, err error) (bool, string, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, string(v), nil
+}
+
 func HeightKey() (k []byte) {
 	return heightKey
 }
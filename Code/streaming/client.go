@@ -0,0 +1,76 @@
+package streaming
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client subscribes to the streams of a [Server].
+type Client struct {
+	conn *websocket.Conn
+
+	// Requests can be sent while another goroutine listens
+	l sync.Mutex
+}
+
+// NewClient connects to the streaming server at [uri], which looks like
+// ws://127.0.0.1:4000.
+func NewClient(uri string) (*Client, error) {
+	conn, resp, err := websocket.DefaultDialer.Dial(uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return &Client{conn: conn}, nil
+}
+
+func (c *Client) SubscribeBlocks() error {
+	return c.Send(&Request{Method: MethodSubscribe, Stream: StreamBlocks})
+}
+
+func (c *Client) SubscribeTxs(address string) error {
+	return c.Send(&Request{Method: MethodSubscribe, Stream: StreamTxs, Address: address})
+}
+
+func (c *Client) SubscribeOrders(pair string) error {
+	return c.Send(&Request{Method: MethodSubscribe, Stream: StreamOrders, Pair: pair})
+}
+
+// Send sends [req] to the server. Its reply is returned by [Listen] like
+// any other message.
+func (c *Client) Send(req *Request) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+// Listen blocks until the next message arrives. If the client fell too far
+// behind, the error is a [websocket.CloseError] with code
+// [websocket.CloseTryAgainLater] and the client should reconnect.
+func (c *Client) Listen() (*Message, error) {
+	_, b, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	var msg Message
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (c *Client) Close() error {
+	c.l.Lock()
+	defer c.l.Unlock()
+	_ = c.conn.WriteMessage(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+	)
+	return c.conn.Close()
+}
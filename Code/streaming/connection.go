@@ -0,0 +1,86 @@
+package streaming
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/gorilla/websocket"
+)
+
+// connection is a WebSocket client of the [Server]. Messages are queued in
+// [send] and written by [writePump], so a slow client never blocks the
+// server.
+type connection struct {
+	ws   *websocket.Conn
+	send chan []byte
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+
+	// Subscriptions are guarded by the lock of the server
+	blocks bool
+	txs    set.Set[string]
+	orders set.Set[string]
+}
+
+func newConnection(ws *websocket.Conn, backlog int) *connection {
+	return &connection{
+		ws:     ws,
+		send:   make(chan []byte, backlog),
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *connection) subscriptions() int {
+	n := c.txs.Len() + c.orders.Len()
+	if c.blocks {
+		n++
+	}
+	return n
+}
+
+// close stops [writePump], which tells the client why with [code] and
+// [text] and closes the connection.
+func (c *connection) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeText = text
+		close(c.closed)
+	})
+}
+
+func (c *connection) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.ws.Close()
+	}()
+	for {
+		select {
+		case msg := <-c.send:
+			if err := c.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+				return
+			}
+			if err := c.ws.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+				return
+			}
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.closed:
+			_ = c.ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(c.closeCode, c.closeText),
+				time.Now().Add(writeWait),
+			)
+			return
+		}
+	}
+}
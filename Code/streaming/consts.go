@@ -0,0 +1,21 @@
+package streaming
+
+import (
+	"math"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	maxRequestSize    = units.KiB
+	maxSubscriptions  = 64
+	writeWait         = 10 * time.Second
+	pongWait          = 60 * time.Second
+	pingPeriod        = (pongWait * 9) / 10
+	readHeaderTimeout = 5 * time.Second
+
+	// Snapshots hold the whole book of a pair so the events that follow can
+	// be applied to it.
+	allOrders = math.MaxInt
+)
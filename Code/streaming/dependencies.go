@@ -0,0 +1,19 @@
+package streaming
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/storage"
+)
+
+type Controller interface {
+	Logger() logging.Logger
+	Orders(pair string, quantity uint64, limit int) *energyledger.Book
+	GetOwnerOrders(context.Context, crypto.PublicKey, ids.ID, int) ([]*storage.OpenOrder, error)
+	GetSymbolAsset(context.Context, string) (bool, ids.ID, error)
+}
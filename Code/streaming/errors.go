@@ -0,0 +1,10 @@
+package streaming
+
+import "errors"
+
+var (
+	ErrInvalidMethod        = errors.New("invalid method")
+	ErrInvalidStream        = errors.New("invalid stream")
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+	ErrNotSubscribed        = errors.New("not subscribed")
+)
@@ -0,0 +1,100 @@
+package streaming
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/rpc"
+)
+
+// Methods of a [Request].
+const (
+	MethodSubscribe   = "subscribe"
+	MethodUnsubscribe = "unsubscribe"
+)
+
+// Streams a client can subscribe to.
+const (
+	// StreamBlocks sends every accepted block.
+	StreamBlocks = "blocks"
+	// StreamTxs sends the result of every tx issued by an address.
+	StreamTxs = "txs"
+	// StreamOrders sends every change to the tracked book of a pair.
+	StreamOrders = "orders"
+)
+
+// Kinds of a [Message].
+const (
+	KindSnapshot     = "snapshot"
+	KindBlock        = "block"
+	KindTx           = "tx"
+	KindOrder        = "order"
+	KindUnsubscribed = "unsubscribed"
+	KindError        = "error"
+)
+
+// Request is sent by clients to change what they are subscribed to.
+type Request struct {
+	Method string `json:"method"`
+	Stream string `json:"stream"`
+
+	// Address is set for [StreamTxs] and Pair for [StreamOrders]. Pair is
+	// denoted as <asset 1>-<asset 2>, where each asset is either an ID or a
+	// registered symbol.
+	Address string `json:"address,omitempty"`
+	Pair    string `json:"pair,omitempty"`
+}
+
+// Message is sent by the server. Every subscription starts with a snapshot,
+// after which it receives the events of each accepted block.
+type Message struct {
+	Kind   string `json:"kind"`
+	Stream string `json:"stream"`
+
+	// Address and Pair are the subscription the message is for. Pair is
+	// always denoted by asset IDs.
+	Address string `json:"address,omitempty"`
+	Pair    string `json:"pair,omitempty"`
+
+	// Height is the last block the server streamed. Snapshots include every
+	// change up to it and events are sent for the blocks after it. It is 0
+	// until a block is accepted after the node starts.
+	Height uint64 `json:"height"`
+
+	Block *Block `json:"block,omitempty"`
+	Tx    *Tx    `json:"tx,omitempty"`
+	Order *Order `json:"order,omitempty"`
+
+	// Book is the snapshot of [StreamOrders] and Orders is the snapshot of
+	// [StreamTxs], holding the open orders of the address.
+	Book   *energyledger.Book `json:"book,omitempty"`
+	Orders []*rpc.OwnerOrder  `json:"orders,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+type Block struct {
+	ID            ids.ID `json:"id"`
+	Parent        ids.ID `json:"parent"`
+	Height        uint64 `json:"height"`
+	Timestamp     int64  `json:"timestamp"`
+	Txs           int    `json:"txs"`
+	UnitPrice     uint64 `json:"unitPrice"`
+	UnitsConsumed uint64 `json:"unitsConsumed"`
+}
+
+type Tx struct {
+	ID      ids.ID `json:"id"`
+	Actor   string `json:"actor"`
+	Success bool   `json:"success"`
+	Units   uint64 `json:"units"`
+	Output  []byte `json:"output"`
+}
+
+// Order is a change to a book, where Event is one of
+// [energyledger.EventAdd], [energyledger.EventUpdate] or
+// [energyledger.EventRemove].
+type Order struct {
+	Event string                    `json:"event"`
+	Order *energyledger.EnergyOrder `json:"order"`
+}
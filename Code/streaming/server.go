@@ -0,0 +1,444 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/rpc"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+// Server streams accepted blocks, tx results and changes to the tracked
+// books to WebSocket clients. A client that falls more than the backlog
+// behind is disconnected and can reconnect to start over from a snapshot.
+type Server struct {
+	c        Controller
+	backlog  int
+	upgrader websocket.Upgrader
+	s        *http.Server
+
+	// l is held from [Begin] until the block is published, so a snapshot
+	// never includes part of a block.
+	l       sync.Mutex
+	height  uint64
+	last    *Block
+	current *Block
+	pending []*event // nil outside of a block
+
+	conns  set.Set[*connection]
+	blocks set.Set[*connection]
+	txs    map[string]set.Set[*connection] // by address
+	orders map[string]set.Set[*connection] // by pair
+}
+
+// event is a message for the subscribers of [key] to [stream].
+type event struct {
+	stream string
+	key    string
+	msg    *Message
+}
+
+// New returns a server that keeps up to [backlog] messages for each client.
+// It does not serve clients until [Listen] is called.
+func New(c Controller, backlog int) *Server {
+	return &Server{
+		c:       c,
+		backlog: backlog,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		txs:    map[string]set.Set[*connection]{},
+		orders: map[string]set.Set[*connection]{},
+	}
+}
+
+// Listen serves clients on [port] until [Close] is called.
+func (s *Server) Listen(port uint16) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	s.s = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() {
+		if err := s.s.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			s.c.Logger().Warn("streaming server stopped", zap.Error(err))
+		}
+	}()
+	s.c.Logger().Info("streaming server listening", zap.String("address", listener.Addr().String()))
+	return nil
+}
+
+// Close stops serving and disconnects every client.
+func (s *Server) Close() error {
+	if s.s == nil {
+		return nil
+	}
+	err := s.s.Close()
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	for _, c := range s.conns.List() {
+		s.disconnect(c, websocket.CloseGoingAway, "shutting down")
+	}
+	return err
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.c.Logger().Debug("unable to upgrade streaming connection", zap.Error(err))
+		return
+	}
+	c := newConnection(ws, s.backlog)
+	s.l.Lock()
+	s.conns.Add(c)
+	s.l.Unlock()
+
+	go c.writePump()
+	go s.readPump(c)
+}
+
+func (s *Server) readPump(c *connection) {
+	defer func() {
+		s.l.Lock()
+		s.disconnect(c, websocket.CloseNormalClosure, "")
+		s.l.Unlock()
+	}()
+
+	c.ws.SetReadLimit(maxRequestSize)
+	if err := c.ws.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
+		return
+	}
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, b, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.handle(c, b)
+	}
+}
+
+// handle applies the request in [b] and replies to it. Subscriptions are
+// answered with a snapshot taken under the same lock as the events that
+// follow it.
+func (s *Server) handle(c *connection, b []byte) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	var (
+		req Request
+		msg *Message
+	)
+	err := json.Unmarshal(b, &req)
+	switch {
+	case err != nil:
+	case req.Method == MethodSubscribe:
+		msg, err = s.subscribe(c, &req)
+	case req.Method == MethodUnsubscribe:
+		msg, err = s.unsubscribe(c, &req)
+	default:
+		err = ErrInvalidMethod
+	}
+	if err != nil {
+		msg = &Message{
+			Kind:    KindError,
+			Stream:  req.Stream,
+			Address: req.Address,
+			Pair:    req.Pair,
+			Height:  s.height,
+			Error:   err.Error(),
+		}
+	}
+	s.send(c, msg)
+}
+
+func (s *Server) subscribe(c *connection, req *Request) (*Message, error) {
+	ctx := context.Background()
+	msg := &Message{
+		Kind:   KindSnapshot,
+		Stream: req.Stream,
+		Height: s.height,
+	}
+	switch req.Stream {
+	case StreamBlocks:
+		if !c.blocks && c.subscriptions() >= maxSubscriptions {
+			return nil, ErrTooManySubscriptions
+		}
+		c.blocks = true
+		s.blocks.Add(c)
+		msg.Block = s.last
+	case StreamTxs:
+		pk, err := utils.ParseAddress(req.Address)
+		if err != nil {
+			return nil, err
+		}
+		addr := utils.Address(pk)
+		if !c.txs.Contains(addr) && c.subscriptions() >= maxSubscriptions {
+			return nil, ErrTooManySubscriptions
+		}
+		orders, err := s.c.GetOwnerOrders(ctx, pk, ids.Empty, allOrders)
+		if err != nil {
+			return nil, err
+		}
+		c.txs.Add(addr)
+		add(s.txs, addr, c)
+		msg.Address = addr
+		msg.Orders = rpc.NewOwnerOrders(orders)
+	case StreamOrders:
+		pair, err := actions.ParsePair(req.Pair, s.lookupSymbol(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if !c.orders.Contains(pair) && c.subscriptions() >= maxSubscriptions {
+			return nil, ErrTooManySubscriptions
+		}
+		c.orders.Add(pair)
+		add(s.orders, pair, c)
+		msg.Pair = pair
+		msg.Book = s.c.Orders(pair, 0, allOrders)
+	default:
+		return nil, ErrInvalidStream
+	}
+	return msg, nil
+}
+
+func (s *Server) unsubscribe(c *connection, req *Request) (*Message, error) {
+	msg := &Message{
+		Kind:   KindUnsubscribed,
+		Stream: req.Stream,
+		Height: s.height,
+	}
+	switch req.Stream {
+	case StreamBlocks:
+		if !c.blocks {
+			return nil, ErrNotSubscribed
+		}
+		c.blocks = false
+		s.blocks.Remove(c)
+	case StreamTxs:
+		pk, err := utils.ParseAddress(req.Address)
+		if err != nil {
+			return nil, err
+		}
+		addr := utils.Address(pk)
+		if !c.txs.Contains(addr) {
+			return nil, ErrNotSubscribed
+		}
+		c.txs.Remove(addr)
+		remove(s.txs, addr, c)
+		msg.Address = addr
+	case StreamOrders:
+		pair, err := actions.ParsePair(req.Pair, s.lookupSymbol(context.Background()))
+		if err != nil {
+			return nil, err
+		}
+		if !c.orders.Contains(pair) {
+			return nil, ErrNotSubscribed
+		}
+		c.orders.Remove(pair)
+		remove(s.orders, pair, c)
+		msg.Pair = pair
+	default:
+		return nil, ErrInvalidStream
+	}
+	return msg, nil
+}
+
+func (s *Server) lookupSymbol(ctx context.Context) actions.SymbolLookup {
+	return func(symbol string) (ids.ID, bool, error) {
+		exists, asset, err := s.c.GetSymbolAsset(ctx, symbol)
+		return asset, exists, err
+	}
+}
+
+func add(m map[string]set.Set[*connection], key string, c *connection) {
+	conns := m[key]
+	conns.Add(c)
+	m[key] = conns
+}
+
+func remove(m map[string]set.Set[*connection], key string, c *connection) {
+	conns := m[key]
+	conns.Remove(c)
+	if conns.Len() == 0 {
+		delete(m, key)
+	}
+}
+
+// disconnect drops the subscriptions of [c] and closes it. It must be called
+// with [s.l] held.
+func (s *Server) disconnect(c *connection, code int, text string) {
+	if !s.conns.Contains(c) {
+		return
+	}
+	s.conns.Remove(c)
+	s.blocks.Remove(c)
+	for addr := range c.txs {
+		remove(s.txs, addr, c)
+	}
+	for pair := range c.orders {
+		remove(s.orders, pair, c)
+	}
+	c.close(code, text)
+}
+
+// send queues [msg] for [c], disconnecting it if its backlog is full. It
+// must be called with [s.l] held.
+func (s *Server) send(c *connection, msg *Message) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		// This should never happen
+		s.c.Logger().Error("unable to marshal streaming message", zap.Error(err))
+		return
+	}
+	s.sendBytes(c, b)
+}
+
+func (s *Server) sendBytes(c *connection, b []byte) {
+	select {
+	case c.send <- b:
+	default:
+		s.c.Logger().Debug("disconnecting slow streaming client")
+		s.disconnect(c, websocket.CloseTryAgainLater, "backlog full")
+	}
+}
+
+// publish sends [msg] to every connection in [conns].
+func (s *Server) publish(conns set.Set[*connection], msg *Message) {
+	if conns.Len() == 0 {
+		return
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		// This should never happen
+		s.c.Logger().Error("unable to marshal streaming message", zap.Error(err))
+		return
+	}
+	for _, c := range conns.List() {
+		s.sendBytes(c, b)
+	}
+}
+
+// Begin starts collecting the events of [blk]. Snapshots wait until the
+// block is published with [Publish] or dropped with [Abort].
+func (s *Server) Begin(blk *chain.StatelessBlock) {
+	s.l.Lock()
+	s.pending = []*event{}
+	s.current = &Block{
+		ID:            blk.ID(),
+		Parent:        blk.Parent(),
+		Height:        blk.Height(),
+		Timestamp:     blk.GetTimestamp(),
+		Txs:           len(blk.Txs),
+		UnitPrice:     blk.GetUnitPrice(),
+		UnitsConsumed: blk.UnitsConsumed,
+	}
+	if len(s.txs) == 0 {
+		return
+	}
+
+	results := blk.Results()
+	for i, tx := range blk.Txs {
+		actor := utils.Address(auth.GetActor(tx.Auth))
+		if _, ok := s.txs[actor]; !ok {
+			continue
+		}
+		result := results[i]
+		s.pending = append(s.pending, &event{
+			stream: StreamTxs,
+			key:    actor,
+			msg: &Message{
+				Kind:    KindTx,
+				Stream:  StreamTxs,
+				Address: actor,
+				Height:  blk.Height(),
+				Tx: &Tx{
+					ID:      tx.ID(),
+					Actor:   actor,
+					Success: result.Success,
+					Units:   result.Units,
+					Output:  result.Output,
+				},
+			},
+		})
+	}
+}
+
+// OrderEvent records a change to the book of [pair] in the block being
+// accepted. Changes made outside of a block, like rebuilding the ledger on
+// startup, are not streamed.
+func (s *Server) OrderEvent(kind string, pair string, order *energyledger.EnergyOrder) {
+	if s.pending == nil {
+		return
+	}
+	if _, ok := s.orders[pair]; !ok {
+		return
+	}
+	s.pending = append(s.pending, &event{
+		stream: StreamOrders,
+		key:    pair,
+		msg: &Message{
+			Kind:   KindOrder,
+			Stream: StreamOrders,
+			Pair:   pair,
+			Height: s.current.Height,
+			Order: &Order{
+				Event: kind,
+				Order: order,
+			},
+		},
+	})
+}
+
+// Publish sends the events of the block passed to [Begin] to subscribers,
+// followed by the block itself.
+func (s *Server) Publish() {
+	defer s.l.Unlock()
+
+	for _, e := range s.pending {
+		switch e.stream {
+		case StreamTxs:
+			s.publish(s.txs[e.key], e.msg)
+		case StreamOrders:
+			s.publish(s.orders[e.key], e.msg)
+		}
+	}
+	s.height = s.current.Height
+	s.last = s.current
+	s.publish(s.blocks, &Message{
+		Kind:   KindBlock,
+		Stream: StreamBlocks,
+		Height: s.height,
+		Block:  s.last,
+	})
+	s.pending = nil
+	s.current = nil
+}
+
+// Abort drops the events of the block passed to [Begin].
+func (s *Server) Abort() {
+	defer s.l.Unlock()
+	s.pending = nil
+	s.current = nil
+}
@@ -0,0 +1,337 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avago_version "github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/vm"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bbehrman10/energyavavm/auth"
+	"github.com/bbehrman10/energyavavm/config"
+	"github.com/bbehrman10/energyavavm/controller"
+	"github.com/bbehrman10/energyavavm/genesis"
+	"github.com/bbehrman10/energyavavm/rpc"
+	"github.com/bbehrman10/energyavavm/utils"
+)
+
+const requestTimeout = 30 * time.Second
+
+// account is a funded key that can sign transactions on the network.
+type account struct {
+	priv    crypto.PrivateKey
+	pk      crypto.PublicKey
+	addr    string
+	factory *auth.ED25519Factory
+}
+
+func newAccount(t *testing.T) *account {
+	priv, err := crypto.GeneratePrivateKey()
+	require.NoError(t, err)
+	pk := priv.PublicKey()
+	return &account{
+		priv:    priv,
+		pk:      pk,
+		addr:    utils.Address(pk),
+		factory: auth.NewED25519Factory(priv),
+	}
+}
+
+// instance is a single embedded VM with its own databases and HTTP server.
+type instance struct {
+	nodeID     ids.NodeID
+	sk         *bls.SecretKey
+	dataDir    string
+	dbManager  manager.Manager
+	vm         *vm.VM
+	toEngine   chan common.Message
+	httpServer *httptest.Server
+	cli        *rpc.JSONRPCClient
+
+	// streamingURI is where the streaming server of the instance listens
+	streamingURI string
+}
+
+// network is a set of VMs running in test mode in this process. Building and
+// gossip are manual, so a test decides exactly when txs move between nodes
+// and when blocks are produced.
+type network struct {
+	t            *testing.T
+	chainID      ids.ID
+	subnetID     ids.ID
+	genesis      *genesis.Genesis
+	genesisBytes []byte
+	trackedPairs []byte
+	instances    []*instance
+}
+
+// newNetwork boots [size] VMs that share [gen] and track every pair. All
+// instances are shut down when the test finishes.
+func newNetwork(t *testing.T, size int, gen *genesis.Genesis) *network {
+	return newTrackingNetwork(t, size, gen, &config.TrackedPair{Pair: "*"})
+}
+
+// newTrackingNetwork is [newNetwork] with only [trackedPairs] tracked.
+func newTrackingNetwork(t *testing.T, size int, gen *genesis.Genesis, trackedPairs ...*config.TrackedPair) *network {
+	require := require.New(t)
+
+	genesisBytes, err := json.Marshal(gen)
+	require.NoError(err)
+	pairBytes, err := json.Marshal(trackedPairs)
+	require.NoError(err)
+
+	n := &network{
+		t:            t,
+		chainID:      ids.GenerateTestID(),
+		subnetID:     ids.GenerateTestID(),
+		genesis:      gen,
+		genesisBytes: genesisBytes,
+		trackedPairs: pairBytes,
+		instances:    make([]*instance, size),
+	}
+	for i := range n.instances {
+		sk, err := bls.NewSecretKey()
+		require.NoError(err)
+		n.instances[i] = &instance{
+			nodeID:    ids.GenerateTestNodeID(),
+			sk:        sk,
+			dataDir:   t.TempDir(),
+			dbManager: manager.NewMemDB(avago_version.CurrentDatabase),
+		}
+		n.start(i)
+	}
+	t.Cleanup(n.shutdown)
+	return n
+}
+
+// start initializes a VM for instance [i] on top of its existing databases.
+func (n *network) start(i int) {
+	require := require.New(n.t)
+
+	inst := n.instances[i]
+	snowCtx := &snow.Context{
+		NetworkID:      1,
+		SubnetID:       n.subnetID,
+		ChainID:        n.chainID,
+		NodeID:         inst.nodeID,
+		Log:            logging.NoLog{},
+		ChainDataDir:   inst.dataDir,
+		Metrics:        metrics.NewOptionalGatherer(),
+		PublicKey:      bls.PublicFromSecretKey(inst.sk),
+		WarpSigner:     warp.NewSigner(inst.sk, n.chainID),
+		ValidatorState: &validators.TestState{},
+	}
+
+	// Every instance needs its own streaming port or they will collide.
+	streamingPort := freePort(n.t)
+	configBytes := []byte(fmt.Sprintf(
+		`{"testMode":true, "trackedPairs":%s, "streamingPort":%d}`,
+		n.trackedPairs,
+		streamingPort,
+	))
+	toEngine := make(chan common.Message, 1)
+	v := controller.New()
+	require.NoError(v.Initialize(
+		context.TODO(),
+		snowCtx,
+		inst.dbManager,
+		n.genesisBytes,
+		nil,
+		configBytes,
+		toEngine,
+		nil,
+		&appSender{n, i},
+	))
+
+	hd, err := v.CreateHandlers(context.TODO())
+	require.NoError(err)
+	mux := http.NewServeMux()
+	for endpoint, handler := range hd {
+		mux.Handle(endpoint, handler.Handler)
+	}
+	inst.vm = v
+	inst.toEngine = toEngine
+	inst.httpServer = httptest.NewServer(mux)
+	inst.cli = rpc.NewJSONRPCClient(inst.httpServer.URL, n.chainID)
+	inst.streamingURI = fmt.Sprintf("ws://127.0.0.1:%d", streamingPort)
+
+	// Force sync ready (to mimic bootstrapping from genesis)
+	v.ForceReady()
+}
+
+// restart shuts instance [i] down and starts it again from its databases, as
+// if the node process was restarted.
+func (n *network) restart(i int) {
+	inst := n.instances[i]
+	inst.httpServer.Close()
+	require.NoError(n.t, inst.vm.Shutdown(context.TODO()))
+	n.start(i)
+}
+
+// restartWithoutMetadata restarts instance [i] after deleting the metaDB of
+// its controller, leaving it with state it has no index of, as after state
+// sync.
+func (n *network) restartWithoutMetadata(i int) {
+	inst := n.instances[i]
+	inst.httpServer.Close()
+	require.NoError(n.t, inst.vm.Shutdown(context.TODO()))
+	require.NoError(n.t, os.RemoveAll(filepath.Join(inst.dataDir, "metadata")))
+	n.start(i)
+}
+
+func (n *network) shutdown() {
+	for _, inst := range n.instances {
+		inst.httpServer.Close()
+		require.NoError(n.t, inst.vm.Shutdown(context.TODO()))
+	}
+}
+
+// cli returns the RPC client of instance [i].
+func (n *network) cli(i int) *rpc.JSONRPCClient {
+	return n.instances[i].cli
+}
+
+// submit signs [action] with [from] and submits it to the mempool of instance
+// [i]. It returns the ID of the submitted tx.
+func (n *network) submit(i int, from *account, action chain.Action) ids.ID {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	submit, tx, _, err := n.cli(i).GenerateTransaction(ctx, action, from.factory)
+	require.NoError(n.t, err)
+	require.NoError(n.t, submit(ctx))
+	return tx.ID()
+}
+
+// gossip sends every tx in the mempool of instance [i] to all other
+// instances.
+func (n *network) gossip(i int) {
+	require.NoError(n.t, n.instances[i].vm.Gossiper().TriggerGossip(context.TODO()))
+}
+
+// buildBlock builds a block on instance [i] and has every instance verify and
+// accept it. It returns the results of the txs included in the block.
+func (n *network) buildBlock(i int) []*chain.Result {
+	require := require.New(n.t)
+	ctx := context.TODO()
+
+	// Manually signal and ack the build request as the engine would
+	builder := n.instances[i]
+	builder.vm.Builder().TriggerBuild()
+	<-builder.toEngine
+
+	blk, err := builder.vm.BuildBlock(ctx)
+	require.NoError(err)
+	require.NoError(blk.Verify(ctx))
+	require.Equal(choices.Processing, blk.Status())
+	require.NoError(builder.vm.SetPreference(ctx, blk.ID()))
+	require.NoError(blk.Accept(ctx))
+
+	for j, inst := range n.instances {
+		if j == i {
+			continue
+		}
+		pblk, err := inst.vm.ParseBlock(ctx, blk.Bytes())
+		require.NoError(err)
+		require.NoError(pblk.Verify(ctx))
+		require.NoError(inst.vm.SetPreference(ctx, pblk.ID()))
+		require.NoError(pblk.Accept(ctx))
+	}
+	for _, inst := range n.instances {
+		lastAccepted, err := inst.vm.LastAccepted(ctx)
+		require.NoError(err)
+		require.Equal(blk.ID(), lastAccepted)
+	}
+	return blk.(*chain.StatelessBlock).Results()
+}
+
+// execute submits [action] to instance [i], gossips it to the other
+// instances and includes it in a new block. It waits until every instance
+// has indexed the tx and returns its result.
+func (n *network) execute(i int, from *account, action chain.Action) (ids.ID, *chain.Result) {
+	txID := n.submit(i, from, action)
+	n.gossip(i)
+	results := n.buildBlock(i)
+	require.Len(n.t, results, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	for _, inst := range n.instances {
+		success, _, err := inst.cli.WaitForTransaction(ctx, txID)
+		require.NoError(n.t, err)
+		require.Equal(n.t, results[0].Success, success)
+	}
+	return txID, results[0]
+}
+
+// freePort returns a TCP port that is not in use at the time of the call.
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+var _ common.AppSender = (*appSender)(nil)
+
+// appSender delivers gossip from one instance directly to every other
+// instance in the network.
+type appSender struct {
+	n    *network
+	from int
+}
+
+func (app *appSender) SendAppGossip(ctx context.Context, appGossipBytes []byte) error {
+	sender := app.n.instances[app.from].nodeID
+	for i, inst := range app.n.instances {
+		if i == app.from {
+			continue
+		}
+		if err := inst.vm.AppGossip(ctx, sender, appGossipBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*appSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppGossipSpecific(context.Context, set.Set[ids.NodeID], []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
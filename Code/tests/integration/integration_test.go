@@ -0,0 +1,2661 @@
+package integration_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bbehrman10/energyavavm/actions"
+	"github.com/bbehrman10/energyavavm/config"
+	"github.com/bbehrman10/energyavavm/energyledger"
+	"github.com/bbehrman10/energyavavm/genesis"
+	"github.com/bbehrman10/energyavavm/storage"
+	"github.com/bbehrman10/energyavavm/streaming"
+)
+
+const initialBalance = 10_000_000
+
+func newGenesis(accounts ...*account) *genesis.Genesis {
+	gen := genesis.Default()
+	gen.WindowTargetBlocks = 1_000_000 // deactivate block fee
+	for _, acct := range accounts {
+		gen.CustomAllocation = append(gen.CustomAllocation, &genesis.CustomAllocation{
+			Address: acct.addr,
+			Energy:  initialBalance,
+		})
+	}
+	return gen
+}
+
+// energyMetadata returns the encoded metadata of a kWh asset produced from
+// [source].
+func energyMetadata(t *testing.T, source string) []byte {
+	metadata, err := (&actions.AssetMetadata{
+		Symbol:   strings.ToUpper(source),
+		Unit:     actions.UnitKWh,
+		Decimals: 0,
+		Source:   source,
+	}).Marshal()
+	require.NoError(t, err)
+	return metadata
+}
+
+func TestGenesisAllocations(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 3, newGenesis(producer, consumer))
+
+	for _, inst := range n.instances {
+		for _, acct := range []*account{producer, consumer} {
+			balance, err := inst.cli.Balance(ctx, acct.addr, ids.Empty)
+			require.NoError(err)
+			require.Equal(uint64(initialBalance), balance)
+		}
+		exists, _, supply, _, _, _, err := inst.cli.Asset(ctx, ids.Empty)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(uint64(2*initialBalance), supply)
+	}
+}
+
+func TestProduceOrderFillConsume(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 3, newGenesis(producer, consumer))
+
+	// Producer registers an energy asset and mints into it
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(100), balance)
+	}
+
+	// Producer offers 50 kWh at 1,000 ETKN per 10 kWh
+	orderID, result := n.execute(2, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  50,
+	})
+	require.True(result.Success)
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(50), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(orderID, book.Asks[0].ID)
+		require.Equal(producer.addr, book.Asks[0].Producer)
+		require.Equal(uint64(50), book.Asks[0].Remaining)
+	}
+
+	// Consumer buys 20 kWh from the order
+	producerBalance, err := n.cli(0).Balance(ctx, producer.addr, ids.Empty)
+	require.NoError(err)
+	_, result = n.execute(0, consumer, &actions.FillEnergyOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 2_000,
+	})
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal(uint64(2_000), or.In)
+	require.Equal(uint64(20), or.Out)
+	require.Equal(uint64(30), or.Remaining)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, ids.Empty)
+		require.NoError(err)
+		require.Equal(producerBalance+2_000, balance)
+
+		balance, err = inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(20), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(uint64(30), book.Asks[0].Remaining)
+	}
+
+	// Consumer uses 15 kWh, which burns it from the asset supply
+	_, result = n.execute(1, consumer, &actions.ConsumeEnergy{
+		Asset: assetID,
+		Value: 15,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(5), balance)
+
+		exists, metadata, supply, owner, warp, _, err := inst.cli.Asset(ctx, assetID)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(energyMetadata(t, "solar"), metadata)
+		require.Equal(uint64(85), supply)
+		require.Equal(producer.addr, owner)
+		require.False(warp)
+	}
+
+	// Overpaying takes what is left and removes the order
+	_, result = n.execute(2, consumer, &actions.FillEnergyOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 5_000,
+	})
+	require.True(result.Success)
+	or, err = actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal(uint64(3_000), or.In)
+	require.Equal(uint64(30), or.Out)
+	require.Zero(or.Remaining)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(35), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Empty(book.Asks)
+	}
+
+	// Filling a removed order fails without moving funds
+	_, result = n.execute(0, consumer, &actions.FillEnergyOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 1_000,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputOrderMissing, result.Output)
+}
+
+func TestTransferEnergy(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 40,
+	})
+	require.True(result.Success)
+
+	// Energy assets move between accounts without touching the supply
+	_, result = n.execute(1, producer, &actions.TransferEnergy{
+		To:    consumer.pk,
+		Asset: assetID,
+		Value: 15,
+		Memo:  []byte("march"),
+	})
+	require.True(result.Success)
+
+	// The native token can be transferred the same way
+	consumerBalance, err := n.cli(0).Balance(ctx, consumer.addr, ids.Empty)
+	require.NoError(err)
+	_, result = n.execute(0, producer, &actions.TransferEnergy{
+		To:    consumer.pk,
+		Asset: ids.Empty,
+		Value: 1_000,
+	})
+	require.True(result.Success)
+
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(25), balance)
+		balance, err = inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(15), balance)
+		balance, err = inst.cli.Balance(ctx, consumer.addr, ids.Empty)
+		require.NoError(err)
+		require.Equal(consumerBalance+1_000, balance)
+
+		_, _, supply, _, _, _, err := inst.cli.Asset(ctx, assetID)
+		require.NoError(err)
+		require.Equal(uint64(40), supply)
+	}
+
+	// Sending more than the balance fails
+	_, result = n.execute(1, consumer, &actions.TransferEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 16,
+	})
+	require.False(result.Success)
+}
+
+func TestExportEnergy(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, recipient := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "hydro"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// Exports lock the kWh as credit for the destination and emit a warp
+	// message it can import
+	destination, other := ids.GenerateTestID(), ids.GenerateTestID()
+	txID, result := n.execute(1, producer, &actions.ExportEnergy{
+		To:          recipient.pk,
+		Asset:       assetID,
+		Value:       30,
+		Destination: destination,
+	})
+	require.True(result.Success)
+	require.NotNil(result.WarpMessage)
+	require.Equal(destination, result.WarpMessage.DestinationChainID)
+	transfer, err := actions.UnmarshalWarpTransfer(result.WarpMessage.Payload)
+	require.NoError(err)
+	require.Equal(&actions.WarpTransfer{
+		To:    recipient.pk,
+		Asset: assetID,
+		Value: 30,
+		TxID:  txID,
+	}, transfer)
+	_, result = n.execute(0, producer, &actions.ExportEnergy{
+		To:          recipient.pk,
+		Asset:       assetID,
+		Value:       20,
+		Destination: destination,
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, &actions.ExportEnergy{
+		To:          recipient.pk,
+		Asset:       assetID,
+		Value:       5,
+		Destination: other,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(45), balance)
+
+		credit, err := inst.cli.Credit(ctx, assetID, destination)
+		require.NoError(err)
+		require.Equal(uint64(50), credit)
+		credit, err = inst.cli.Credit(ctx, assetID, other)
+		require.NoError(err)
+		require.Equal(uint64(5), credit)
+
+		// Credited kWh are locked, not burned
+		_, _, supply, _, _, _, err := inst.cli.Asset(ctx, assetID)
+		require.NoError(err)
+		require.Equal(uint64(100), supply)
+	}
+
+	// Every export must name its destination
+	_, _, _, err = n.cli(0).GenerateTransaction(ctx, &actions.ExportEnergy{
+		To:    recipient.pk,
+		Asset: assetID,
+		Value: 5,
+	}, producer.factory)
+	require.Error(err)
+
+	// Only imported assets can be returned and burned
+	_, result = n.execute(1, producer, &actions.ExportEnergy{
+		To:          recipient.pk,
+		Asset:       assetID,
+		Value:       5,
+		Return:      true,
+		Destination: destination,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputNotWarpAsset, result.Output)
+	_, result = n.execute(0, producer, &actions.ExportEnergy{
+		To:          recipient.pk,
+		Asset:       actions.ImportedAssetID(assetID, destination),
+		Value:       5,
+		Return:      true,
+		Destination: destination,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetMissing, result.Output)
+
+	// Exports can't exceed the balance
+	_, result = n.execute(1, producer, &actions.ExportEnergy{
+		To:          recipient.pk,
+		Asset:       assetID,
+		Value:       46,
+		Destination: destination,
+	})
+	require.False(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(45), balance)
+
+		credit, err := inst.cli.Credit(ctx, assetID, destination)
+		require.NoError(err)
+		require.Equal(uint64(50), credit)
+	}
+}
+
+func TestMeteredProduction(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, other := newAccount(t), newAccount(t)
+	meter := newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, other))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+
+	// A 4 kW array that already shows 1,500 Wh
+	meterID, result := n.execute(0, producer, &actions.RegisterMeter{
+		Meter:    meter.pk,
+		Capacity: 4_000,
+		Reading:  1_500,
+	})
+	require.True(result.Success)
+
+	reading := func(start, end int64, wh uint64, signer *account) *actions.ProduceMeteredEnergy {
+		return &actions.ProduceMeteredEnergy{
+			To:        producer.pk,
+			Asset:     assetID,
+			Meter:     meterID,
+			Start:     start,
+			End:       end,
+			Reading:   wh,
+			Signature: crypto.Sign(actions.ReadingMessage(meterID, start, end, wh), signer.priv),
+		}
+	}
+	now := time.Now().Unix()
+
+	// 3,700 Wh in an hour is within 4 kW and mints 4 kWh (2 through 5)
+	_, result = n.execute(1, producer, reading(now-7200, now-3600, 5_200, meter))
+	require.True(result.Success)
+
+	// Readings must be signed by the meter
+	_, result = n.execute(1, producer, reading(now-3600, now-1800, 5_300, producer))
+	require.False(result.Success)
+	require.Equal(actions.OutputInvalidMeterSignature, result.Output)
+
+	// Intervals cannot overlap the last accepted reading
+	_, result = n.execute(0, producer, reading(now-5400, now-1800, 5_300, meter))
+	require.False(result.Success)
+	require.Equal(actions.OutputIntervalOverlap, result.Output)
+
+	// Another producer cannot mint the meter's readings into its own asset
+	otherID, result := n.execute(1, other, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+	stolen := reading(now-3600, now-1800, 6_900, meter)
+	stolen.To = other.pk
+	stolen.Asset = otherID
+	_, result = n.execute(1, other, stolen)
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+
+	// 2,100 Wh in half an hour is more than 4 kW can produce
+	_, result = n.execute(0, producer, reading(now-3600, now-1800, 7_300, meter))
+	require.False(result.Success)
+	require.Equal(actions.OutputExceedsCapacity, result.Output)
+
+	// The 200 Wh left over from the first reading is credited now
+	_, result = n.execute(1, producer, reading(now-3600, now-1800, 6_900, meter))
+	require.True(result.Success)
+
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(5), balance)
+
+		_, _, supply, _, _, _, err := inst.cli.Asset(ctx, assetID)
+		require.NoError(err)
+		require.Equal(uint64(5), supply)
+	}
+}
+
+func TestCertificates(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	meter := newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+	meterID, result := n.execute(0, producer, &actions.RegisterMeter{
+		Meter:      meter.pk,
+		Capacity:   3_000_000,
+		Technology: []byte("wind"),
+		Region:     []byte("ERCOT"),
+	})
+	require.True(result.Success)
+
+	reading := func(start, end int64, wh uint64, certificates uint8) *actions.ProduceMeteredEnergy {
+		return &actions.ProduceMeteredEnergy{
+			To:           producer.pk,
+			Asset:        assetID,
+			Meter:        meterID,
+			Start:        start,
+			End:          end,
+			Reading:      wh,
+			Signature:    crypto.Sign(actions.ReadingMessage(meterID, start, end, wh), meter.priv),
+			Certificates: certificates,
+		}
+	}
+	now := time.Now().Unix()
+
+	// 2.5 MWh only backs 2 certificates
+	_, result = n.execute(1, producer, reading(now-3600, now-1, 2_500_000, 3))
+	require.False(result.Success)
+	require.Equal(actions.OutputTooManyCertificates, result.Output)
+	txID, result := n.execute(1, producer, reading(now-3600, now-1, 2_500_000, 2))
+	require.True(result.Success)
+
+	certificateID := actions.CertificateID(txID, 0)
+	for i := uint8(0); i < 2; i++ {
+		exists, certificate, err := n.cli(0).Certificate(ctx, actions.CertificateID(txID, i))
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(meterID, certificate.Meter)
+		require.Equal([]byte("wind"), certificate.Technology)
+		require.Equal([]byte("ERCOT"), certificate.Region)
+		require.Equal(now-3600, certificate.Start)
+		require.Equal(now-1, certificate.End)
+		require.Equal(producer.addr, certificate.Owner)
+		require.False(certificate.Retired)
+	}
+	exists, _, err := n.cli(0).Certificate(ctx, actions.CertificateID(txID, 2))
+	require.NoError(err)
+	require.False(exists)
+
+	// Only the owner can move a certificate
+	_, result = n.execute(0, consumer, &actions.TransferCertificate{
+		Certificate: certificateID,
+		To:          consumer.pk,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	_, result = n.execute(0, producer, &actions.TransferCertificate{
+		Certificate: certificateID,
+		To:          consumer.pk,
+	})
+	require.True(result.Success)
+
+	// A certificate can be retired exactly once
+	_, result = n.execute(1, consumer, &actions.RetireCertificate{
+		Certificate: certificateID,
+		Beneficiary: []byte("Acme Corp"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, consumer, &actions.RetireCertificate{
+		Certificate: certificateID,
+		Beneficiary: []byte("Someone Else"),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputCertificateRetired, result.Output)
+	_, result = n.execute(0, consumer, &actions.TransferCertificate{
+		Certificate: certificateID,
+		To:          producer.pk,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputCertificateRetired, result.Output)
+
+	for _, inst := range n.instances {
+		exists, certificate, err := inst.cli.Certificate(ctx, certificateID)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(consumer.addr, certificate.Owner)
+		require.True(certificate.Retired)
+		require.Equal([]byte("Acme Corp"), certificate.Beneficiary)
+	}
+}
+
+func TestOrderExpiry(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// Orders that are already expired are rejected
+	_, result = n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  50,
+		Expiry:  time.Now().Unix() - 1,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputOrderExpired, result.Output)
+
+	// Producer offers 50 kWh for a couple of seconds
+	expiry := time.Now().Unix() + 2
+	orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  50,
+		Expiry:  expiry,
+	})
+	require.True(result.Success)
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(expiry, book.Asks[0].Expiry)
+	}
+
+	// Live orders can't be reclaimed
+	reclaim := &actions.ReclaimExpiredOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		Out:   assetID,
+	}
+	_, result = n.execute(1, consumer, reclaim)
+	require.False(result.Success)
+	require.Equal(actions.OutputOrderNotExpired, result.Output)
+
+	// Wait for the order to expire
+	time.Sleep(time.Until(time.Unix(expiry+1, 0)))
+
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 1_000,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputOrderExpired, result.Output)
+	for _, inst := range n.instances {
+		// The ledger drops the order once it sees a block past its expiry
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Empty(book.Asks)
+
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Zero(balance)
+	}
+
+	// Anyone can reclaim the order and the supply goes back to the owner
+	_, result = n.execute(1, consumer, reclaim)
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(100), balance)
+	}
+	_, result = n.execute(0, producer, &actions.CloseEnergyOrder{
+		Order: orderID,
+		Out:   assetID,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputOrderMissing, result.Output)
+}
+
+func TestBidOrders(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "hydro"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// Orders that lock up ETKN are always bids
+	_, result = n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:    actions.SideAsk,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 1_000,
+		Supply:  5_000,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputSideMismatch, result.Output)
+
+	// Consumer bids for 50 kWh at 1,000 ETKN per 10 kWh and producer asks
+	// 1,200 ETKN per 10 kWh for 30 kWh
+	bidID, result := n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 1_000,
+		Supply:  5_000,
+	})
+	require.True(result.Success)
+	askID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		Side:    actions.SideAsk,
+		In:      ids.Empty,
+		InTick:  1_200,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  30,
+	})
+	require.True(result.Success)
+
+	// Both orders are listed in the same book
+	pair := actions.PairID(ids.Empty, assetID)
+	require.Equal(pair, actions.BookID(actions.SideBid, assetID, ids.Empty))
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Bids, 1)
+		require.Equal(bidID, book.Bids[0].ID)
+		require.Equal(actions.SideBid, book.Bids[0].Side)
+		require.Equal(uint64(5_000), book.Bids[0].Remaining)
+		require.Len(book.Asks, 1)
+		require.Equal(askID, book.Asks[0].ID)
+		require.Equal(actions.SideAsk, book.Asks[0].Side)
+	}
+
+	// A higher bid becomes the best bid
+	betterBidID, result := n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 1_100,
+		Supply:  1_100,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Bids, 2)
+		require.Equal(betterBidID, book.Bids[0].ID)
+	}
+
+	// Producer sells 20 kWh into the first bid
+	_, result = n.execute(0, producer, &actions.FillEnergyOrder{
+		Order: bidID,
+		Owner: consumer.pk,
+		In:    assetID,
+		Out:   ids.Empty,
+		Value: 20,
+	})
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal(uint64(20), or.In)
+	require.Equal(uint64(2_000), or.Out)
+	require.Equal(uint64(3_000), or.Remaining)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(50), balance)
+
+		balance, err = inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(20), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Bids, 2)
+		require.Equal(uint64(3_000), book.Bids[1].Remaining)
+	}
+
+	// Closing the bid refunds the locked ETKN
+	_, result = n.execute(1, consumer, &actions.CloseEnergyOrder{
+		Order: bidID,
+		Out:   ids.Empty,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Bids, 1)
+		require.Equal(betterBidID, book.Bids[0].ID)
+	}
+}
+
+func TestSubmitLimitOrder(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// Producer lists three asks at 1,200, 1,000 and 1,500 ETKN per 10 kWh
+	ask := func(inTick uint64, supply uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			Side:    actions.SideAsk,
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: 10,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+	mid, best, worst := ask(1_200, 30), ask(1_000, 20), ask(1_500, 20)
+
+	// Consumer bids for up to 60 kWh at 1,300 ETKN per 10 kWh. The matches
+	// are crossed in price priority and stop at the limit.
+	matches := []*actions.OrderMatch{
+		{Order: mid, Owner: producer.pk},
+		{Order: worst, Owner: producer.pk},
+		{Order: best, Owner: producer.pk},
+	}
+	_, result = n.execute(1, consumer, &actions.SubmitLimitOrder{
+		Side:    actions.SideAsk,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 1_300,
+		Supply:  7_800,
+		Matches: matches,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputSideMismatch, result.Output)
+	bidID, result := n.execute(1, consumer, &actions.SubmitLimitOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 1_300,
+		Supply:  7_800,
+		Matches: matches,
+	})
+	require.True(result.Success)
+	lr, err := actions.UnmarshalLimitOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal([]*actions.OrderFill{
+		{Order: best, In: 2_000, Out: 20, Remaining: 0},
+		{Order: mid, In: 3_600, Out: 30, Remaining: 0},
+	}, lr.Fills)
+
+	// 2,200 ETKN were left, so 1,300 are listed and the rest is refunded
+	require.Equal(uint64(1_300), lr.Listed)
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(50), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(worst, book.Asks[0].ID)
+		require.Len(book.Bids, 1)
+		require.Equal(bidID, book.Bids[0].ID)
+		require.Equal(uint64(1_300), book.Bids[0].Remaining)
+	}
+
+	// Producer sells 10 kWh into the listed bid. Orders that no longer exist
+	// are skipped.
+	_, result = n.execute(0, producer, &actions.SubmitLimitOrder{
+		Side:    actions.SideAsk,
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  10,
+		Matches: []*actions.OrderMatch{
+			{Order: best, Owner: producer.pk},
+			{Order: bidID, Owner: consumer.pk},
+		},
+	})
+	require.True(result.Success)
+	lr, err = actions.UnmarshalLimitOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal([]*actions.OrderFill{
+		{Order: bidID, In: 10, Out: 1_300, Remaining: 0},
+	}, lr.Fills)
+	require.Zero(lr.Listed)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(60), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Empty(book.Bids)
+	}
+
+	// Matching an order on the same side is rejected
+	_, result = n.execute(1, consumer, &actions.SubmitLimitOrder{
+		Side:    actions.SideAsk,
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  10,
+		Matches: []*actions.OrderMatch{{Order: worst, Owner: producer.pk}},
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongSide, result.Output)
+}
+
+func TestFillBestEnergyOrders(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// Producer lists asks at 1,000, 1,100 and 1,500 ETKN per 10 kWh
+	ask := func(inTick uint64, supply uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: 10,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+	first, second, third := ask(1_000, 10), ask(1_100, 20), ask(1_500, 30)
+	candidates := []*actions.OrderMatch{
+		{Order: first, Owner: producer.pk},
+		{Order: ids.GenerateTestID(), Owner: producer.pk}, // gone
+		{Order: second, Owner: producer.pk},
+		{Order: third, Owner: producer.pk},
+	}
+
+	// Buying 30 kWh costs 3,200 ETKN, so a cap of 3,000 fails entirely
+	_, result = n.execute(1, consumer, &actions.FillBestEnergyOrders{
+		In:       ids.Empty,
+		Out:      assetID,
+		Quantity: 30,
+		MaxIn:    3_000,
+		Orders:   candidates,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputMaxInputExceeded, result.Output)
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Zero(balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 3)
+	}
+
+	// With a higher cap the two best orders are swept
+	_, result = n.execute(1, consumer, &actions.FillBestEnergyOrders{
+		In:       ids.Empty,
+		Out:      assetID,
+		Quantity: 30,
+		MaxIn:    3_500,
+		Orders:   candidates,
+	})
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrdersResult(result.Output)
+	require.NoError(err)
+	require.Equal(&actions.EnergyOrdersResult{
+		Fills: []*actions.OrderFill{
+			{Order: first, In: 1_000, Out: 10, Remaining: 0},
+			{Order: second, In: 2_200, Out: 20, Remaining: 0},
+		},
+		In:  3_200,
+		Out: 30,
+	}, or)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(30), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(third, book.Asks[0].ID)
+		require.Equal(uint64(30), book.Asks[0].Remaining)
+	}
+
+	// Nothing is left to fill from the swept orders
+	_, result = n.execute(1, consumer, &actions.FillBestEnergyOrders{
+		In:       ids.Empty,
+		Out:      assetID,
+		Quantity: 10,
+		MaxIn:    1_000,
+		Orders:   candidates[:3],
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputNothingFilled, result.Output)
+}
+
+func TestOrderFlags(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// Producer lists 30 kWh all-or-none and 50 kWh with a 20 kWh minimum
+	allOrNoneID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:        ids.Empty,
+		InTick:    1_000,
+		Out:       assetID,
+		OutTick:   10,
+		Supply:    30,
+		AllOrNone: true,
+	})
+	require.True(result.Success)
+	minFillID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  50,
+		MinFill: 20,
+	})
+	require.True(result.Success)
+
+	// The ledger only surfaces the orders a taker can fill
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 2)
+
+		book, err = inst.cli.FillableOrders(ctx, pair, 10)
+		require.NoError(err)
+		require.Empty(book.Asks)
+
+		book, err = inst.cli.FillableOrders(ctx, pair, 20)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(minFillID, book.Asks[0].ID)
+
+		book, err = inst.cli.FillableOrders(ctx, pair, 30)
+		require.NoError(err)
+		require.Len(book.Asks, 2)
+	}
+
+	// Each violation has its own output
+	fill := func(order ids.ID, value uint64, fillOrKill bool, minReceived uint64) *chain.Result {
+		_, result := n.execute(1, consumer, &actions.FillEnergyOrder{
+			Order:       order,
+			Owner:       producer.pk,
+			In:          ids.Empty,
+			Out:         assetID,
+			Value:       value,
+			FillOrKill:  fillOrKill,
+			MinReceived: minReceived,
+		})
+		return result
+	}
+	for _, tt := range []struct {
+		order       ids.ID
+		value       uint64
+		fillOrKill  bool
+		minReceived uint64
+		output      []byte
+	}{
+		{allOrNoneID, 2_000, false, 0, actions.OutputOrderAllOrNone},
+		{minFillID, 1_000, false, 0, actions.OutputBelowMinFill},
+		{minFillID, 6_000, true, 0, actions.OutputFillOrKill},
+		{minFillID, 2_000, false, 30, actions.OutputBelowMinReceived},
+	} {
+		result := fill(tt.order, tt.value, tt.fillOrKill, tt.minReceived)
+		require.False(result.Success)
+		require.Equal(tt.output, result.Output)
+	}
+
+	// Fills that respect the flags go through
+	result = fill(allOrNoneID, 3_000, true, 30)
+	require.True(result.Success)
+	result = fill(minFillID, 6_000, false, 50)
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal(uint64(5_000), or.In)
+	require.Equal(uint64(50), or.Out)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(80), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Empty(book.Asks)
+	}
+}
+
+func TestAmendEnergyOrder(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	ask := func(inTick uint64, supply uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: 10,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+	cheap, pricey := ask(1_000, 20), ask(1_200, 30)
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 2)
+		require.Equal(cheap, book.Asks[0].ID)
+	}
+
+	// Only the owner can amend an order
+	amend := &actions.AmendEnergyOrder{
+		Order:   pricey,
+		Out:     assetID,
+		InTick:  900,
+		OutTick: 10,
+		Supply:  50,
+	}
+	_, result = n.execute(1, consumer, amend)
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+
+	// Repricing below the other ask and raising the supply locks up 20 kWh
+	// more and moves the order to the top of the book
+	_, result = n.execute(0, producer, amend)
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(30), balance)
+
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 2)
+		require.Equal(pricey, book.Asks[0].ID)
+		require.Equal(uint64(900), book.Asks[0].EnergyAmount)
+		require.Equal(uint64(50), book.Asks[0].Remaining)
+	}
+
+	// Lowering the supply refunds the difference
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   pricey,
+		Out:     assetID,
+		InTick:  900,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(70), balance)
+	}
+
+	// Fills use the amended price
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: pricey,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 900,
+	})
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal(uint64(10), or.Out)
+	require.Zero(or.Remaining)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(cheap, book.Asks[0].ID)
+	}
+
+	// An order can't be amended to less than its minimum fill unless it is
+	// all or none anyway
+	minFill, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  30,
+		MinFill: 20,
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, &actions.AmendEnergyOrder{
+		Order:   minFill,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputSupplyBelowMinFill, result.Output)
+	_, result = n.execute(1, producer, &actions.AmendEnergyOrder{
+		Order:   minFill,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  20,
+	})
+	require.True(result.Success)
+}
+
+func TestTradingFees(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer, treasury := newAccount(t), newAccount(t), newAccount(t)
+	gen := newGenesis(producer, consumer)
+	gen.MakerFeeBips = 100 // 1%
+	gen.TakerFeeBips = 50  // 0.5%
+	gen.Treasury = treasury.addr
+	n := newNetwork(t, 3, gen)
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 400,
+	})
+	require.True(result.Success)
+
+	// Producer offers 400 kWh at 1,000 ETKN per 100 kWh
+	orderID, result := n.execute(2, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 100,
+		Supply:  400,
+	})
+	require.True(result.Success)
+
+	// Fills must pay the treasury set in genesis
+	_, result = n.execute(0, consumer, &actions.FillEnergyOrder{
+		Order:    orderID,
+		Owner:    producer.pk,
+		In:       ids.Empty,
+		Out:      assetID,
+		Value:    2_000,
+		Treasury: consumer.pk,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongTreasury, result.Output)
+
+	// The taker fee counts against the minimum received
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order:       orderID,
+		Owner:       producer.pk,
+		In:          ids.Empty,
+		Out:         assetID,
+		Value:       2_000,
+		MinReceived: 200,
+		Treasury:    treasury.pk,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputBelowMinReceived, result.Output)
+
+	// Consumer buys 200 kWh, paying 1% to the treasury out of the producer's
+	// proceeds and 0.5% out of the energy received
+	producerBalance, err := n.cli(0).Balance(ctx, producer.addr, ids.Empty)
+	require.NoError(err)
+	_, result = n.execute(2, consumer, &actions.FillEnergyOrder{
+		Order:    orderID,
+		Owner:    producer.pk,
+		In:       ids.Empty,
+		Out:      assetID,
+		Value:    2_000,
+		Treasury: treasury.pk,
+	})
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal(uint64(2_000), or.In)
+	require.Equal(uint64(199), or.Out)
+	require.Equal(uint64(200), or.Remaining)
+	require.Equal(uint64(20), or.MakerFee)
+	require.Equal(uint64(1), or.TakerFee)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, ids.Empty)
+		require.NoError(err)
+		require.Equal(producerBalance+1_980, balance)
+
+		balance, err = inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(199), balance)
+
+		balance, err = inst.cli.Balance(ctx, treasury.addr, ids.Empty)
+		require.NoError(err)
+		require.Equal(uint64(20), balance)
+
+		balance, err = inst.cli.Balance(ctx, treasury.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(1), balance)
+
+		book, err := inst.cli.Orders(ctx, actions.PairID(ids.Empty, assetID))
+		require.NoError(err)
+		require.Len(book.Asks, 1)
+		require.Equal(uint64(200), book.Asks[0].Remaining)
+	}
+
+	// Fills across several orders and limit orders that cross the book pay
+	// the same fees on every match
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 800,
+	})
+	require.True(result.Success)
+	secondID, result := n.execute(1, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 200,
+		Supply:  800,
+	})
+	require.True(result.Success)
+	fillBest := &actions.FillBestEnergyOrders{
+		In:       ids.Empty,
+		Out:      assetID,
+		Quantity: 400,
+		MaxIn:    2_000,
+		Orders:   []*actions.OrderMatch{{Order: secondID, Owner: producer.pk}},
+		Treasury: consumer.pk,
+	}
+	_, result = n.execute(2, consumer, fillBest)
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongTreasury, result.Output)
+	fillBest.Treasury = treasury.pk
+	_, result = n.execute(0, consumer, fillBest)
+	require.True(result.Success)
+	ordersResult, err := actions.UnmarshalOrdersResult(result.Output)
+	require.NoError(err)
+	require.Equal(&actions.EnergyOrdersResult{
+		Fills: []*actions.OrderFill{
+			{Order: secondID, In: 2_000, Out: 398, Remaining: 400, MakerFee: 20, TakerFee: 2},
+		},
+		In:  2_000,
+		Out: 398,
+	}, ordersResult)
+
+	// The bid pays the maker fee in ETKN and the taker fee in kWh
+	_, result = n.execute(1, consumer, &actions.SubmitLimitOrder{
+		Side:     actions.SideBid,
+		In:       assetID,
+		InTick:   200,
+		Out:      ids.Empty,
+		OutTick:  1_000,
+		Supply:   2_000,
+		Matches:  []*actions.OrderMatch{{Order: secondID, Owner: producer.pk}},
+		Treasury: treasury.pk,
+	})
+	require.True(result.Success)
+	limitResult, err := actions.UnmarshalLimitOrderResult(result.Output)
+	require.NoError(err)
+	require.Equal([]*actions.OrderFill{
+		{Order: secondID, In: 2_000, Out: 398, Remaining: 0, MakerFee: 20, TakerFee: 2},
+	}, limitResult.Fills)
+	require.Zero(limitResult.Listed)
+	for _, inst := range n.instances {
+		balance, err := inst.cli.Balance(ctx, producer.addr, ids.Empty)
+		require.NoError(err)
+		require.Equal(producerBalance+1_980*3, balance)
+
+		balance, err = inst.cli.Balance(ctx, consumer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(199+398*2), balance)
+
+		balance, err = inst.cli.Balance(ctx, treasury.addr, ids.Empty)
+		require.NoError(err)
+		require.Equal(uint64(60), balance)
+
+		balance, err = inst.cli.Balance(ctx, treasury.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(5), balance)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	operator, producer, consumer := newAccount(t), newAccount(t), newAccount(t)
+	meter := newAccount(t)
+	gen := newGenesis(operator, producer, consumer)
+	gen.Operators = []string{operator.addr}
+	n := newNetwork(t, 3, gen)
+
+	for _, inst := range n.instances {
+		roles, err := inst.cli.Roles(ctx, operator.addr)
+		require.NoError(err)
+		require.Equal(storage.RoleOperator, roles)
+
+		roles, err = inst.cli.Roles(ctx, producer.addr)
+		require.NoError(err)
+		require.Zero(roles)
+	}
+
+	// Producer needs the producer role to create an asset and can't grant it
+	// to itself
+	_, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	registerMeter := &actions.RegisterMeter{
+		Meter:    meter.pk,
+		Capacity: 4_000,
+	}
+	_, result = n.execute(2, producer, registerMeter)
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	_, result = n.execute(1, producer, &actions.GrantRole{
+		To:   producer.pk,
+		Role: storage.RoleProducer,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+
+	// Operator licenses the producer
+	_, result = n.execute(2, operator, &actions.GrantRole{
+		To:   producer.pk,
+		Role: storage.RoleProducer,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, operator, &actions.GrantRole{
+		To:   producer.pk,
+		Role: storage.RoleProducer,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputRoleExists, result.Output)
+	for _, inst := range n.instances {
+		roles, err := inst.cli.Roles(ctx, producer.addr)
+		require.NoError(err)
+		require.Equal(storage.RoleProducer, roles)
+	}
+
+	assetID, result := n.execute(1, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(2, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+	meterID, result := n.execute(0, producer, registerMeter)
+	require.True(result.Success)
+	now := time.Now().Unix()
+	reading := func(start, end int64, wh uint64) *actions.ProduceMeteredEnergy {
+		return &actions.ProduceMeteredEnergy{
+			To:        producer.pk,
+			Asset:     assetID,
+			Meter:     meterID,
+			Start:     start,
+			End:       end,
+			Reading:   wh,
+			Signature: crypto.Sign(actions.ReadingMessage(meterID, start, end, wh), meter.priv),
+		}
+	}
+	_, result = n.execute(1, producer, reading(now-7200, now-3600, 2_000))
+	require.True(result.Success)
+	askID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  50,
+	})
+	require.True(result.Success)
+
+	// Consumer needs a role to bid
+	bid := &actions.CreateEnergyOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 900,
+		Supply:  900,
+	}
+	_, result = n.execute(1, consumer, bid)
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	_, result = n.execute(2, operator, &actions.GrantRole{
+		To:   consumer.pk,
+		Role: storage.RoleConsumer,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, consumer, bid)
+	require.True(result.Success)
+
+	// Once revoked, the producer can no longer issue energy
+	_, result = n.execute(1, operator, &actions.RevokeRole{
+		From: producer.pk,
+		Role: storage.RoleProducer,
+	})
+	require.True(result.Success)
+	_, result = n.execute(2, operator, &actions.RevokeRole{
+		From: producer.pk,
+		Role: storage.RoleProducer,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputRoleMissing, result.Output)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	_, result = n.execute(1, producer, reading(now-3600, now-1800, 3_000))
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	_, result = n.execute(2, producer, registerMeter)
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+
+	// Listed orders can still be shrunk, but not grown
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   askID,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  60,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputUnauthorized, result.Output)
+	_, result = n.execute(1, producer, &actions.AmendEnergyOrder{
+		Order:   askID,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  40,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		roles, err := inst.cli.Roles(ctx, producer.addr)
+		require.NoError(err)
+		require.Zero(roles)
+
+		balance, err := inst.cli.Balance(ctx, producer.addr, assetID)
+		require.NoError(err)
+		require.Equal(uint64(62), balance)
+	}
+
+	// Operators can't revoke their own role, so roles can always be managed
+	_, result = n.execute(2, operator, &actions.RevokeRole{
+		From: operator.pk,
+		Role: storage.RoleOperator,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputSelfRevoke, result.Output)
+	for _, inst := range n.instances {
+		roles, err := inst.cli.Roles(ctx, operator.addr)
+		require.NoError(err)
+		require.Equal(storage.RoleOperator, roles)
+	}
+}
+
+func TestAssetAdministration(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer, rotated := newAccount(t), newAccount(t), newAccount(t)
+	meter := newAccount(t)
+	n := newNetwork(t, 3, newGenesis(producer, consumer, rotated))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+	orderID, result := n.execute(2, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  50,
+	})
+	require.True(result.Success)
+
+	// Only the owner can administer the asset
+	_, result = n.execute(0, consumer, &actions.UpdateAssetMetadata{
+		Asset:    assetID,
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	metadataTx, result := n.execute(1, producer, &actions.UpdateAssetMetadata{
+		Asset:    assetID,
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+
+	// While frozen, the asset can't be produced, transferred, listed or filled
+	freezeTx, result := n.execute(2, producer, &actions.FreezeAsset{Asset: assetID})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.FreezeAsset{Asset: assetID})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	for _, inst := range n.instances {
+		exists, metadata, _, _, _, frozen, err := inst.cli.Asset(ctx, assetID)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(energyMetadata(t, "wind"), metadata)
+		require.True(frozen)
+	}
+	_, result = n.execute(1, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 10,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	transfer := &actions.TransferEnergy{
+		To:    consumer.pk,
+		Asset: assetID,
+		Value: 10,
+	}
+	_, result = n.execute(2, producer, transfer)
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	fill := &actions.FillEnergyOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 1_000,
+	}
+	_, result = n.execute(0, consumer, fill)
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	_, result = n.execute(1, producer, &actions.ExportEnergy{
+		To:          producer.pk,
+		Asset:       assetID,
+		Value:       10,
+		Destination: ids.GenerateTestID(),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	_, result = n.execute(2, producer, &actions.AmendEnergyOrder{
+		Order:   orderID,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  60,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	_, result = n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+	_, result = n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 1_000,
+		Supply:  1_000,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetFrozen, result.Output)
+
+	// Once unfrozen, trading resumes
+	unfreezeTx, result := n.execute(1, producer, &actions.UnfreezeAsset{Asset: assetID})
+	require.True(result.Success)
+	_, result = n.execute(2, producer, transfer)
+	require.True(result.Success)
+	_, result = n.execute(0, consumer, fill)
+	require.True(result.Success)
+
+	// After a key rotation, only the new owner can produce, and meters
+	// registered by the old key must be handed over first
+	meterID, result := n.execute(2, producer, &actions.RegisterMeter{
+		Meter:    meter.pk,
+		Capacity: 4_000,
+	})
+	require.True(result.Success)
+	now := time.Now().Unix()
+	reading := func(start, end int64, wh uint64) *actions.ProduceMeteredEnergy {
+		return &actions.ProduceMeteredEnergy{
+			To:        rotated.pk,
+			Asset:     assetID,
+			Meter:     meterID,
+			Start:     start,
+			End:       end,
+			Reading:   wh,
+			Signature: crypto.Sign(actions.ReadingMessage(meterID, start, end, wh), meter.priv),
+		}
+	}
+	ownerTx, result := n.execute(1, producer, &actions.UpdateAssetOwner{
+		Asset: assetID,
+		Owner: rotated.pk,
+	})
+	require.True(result.Success)
+	_, result = n.execute(2, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 10,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	_, result = n.execute(0, rotated, &actions.ProduceEnergy{
+		To:    rotated.pk,
+		Asset: assetID,
+		Value: 10,
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, reading(now-7200, now-3600, 1_000))
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	_, result = n.execute(2, rotated, reading(now-7200, now-3600, 2_000))
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	_, result = n.execute(0, rotated, &actions.TransferMeter{Meter: meterID, To: rotated.pk})
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	_, result = n.execute(0, producer, &actions.TransferMeter{Meter: meterID, To: rotated.pk})
+	require.True(result.Success)
+	_, result = n.execute(1, producer, reading(now-7200, now-3600, 2_000))
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	_, result = n.execute(2, rotated, reading(now-7200, now-3600, 2_000))
+	require.True(result.Success)
+
+	// Every change is recorded for auditing
+	for _, inst := range n.instances {
+		_, _, supply, owner, _, _, err := inst.cli.Asset(ctx, assetID)
+		require.NoError(err)
+		require.Equal(uint64(112), supply)
+		require.Equal(rotated.addr, owner)
+
+		changes, err := inst.cli.AssetChanges(ctx, assetID)
+		require.NoError(err)
+		require.Len(changes, 4)
+		for i, expected := range []struct {
+			txID  ids.ID
+			kind  uint8
+			value []byte
+		}{
+			{metadataTx, storage.AssetChangeMetadata, energyMetadata(t, "wind")},
+			{freezeTx, storage.AssetChangeFreeze, []byte{}},
+			{unfreezeTx, storage.AssetChangeUnfreeze, []byte{}},
+			{ownerTx, storage.AssetChangeOwner, rotated.pk[:]},
+		} {
+			require.Equal(expected.txID, changes[i].TxID)
+			require.Equal(expected.kind, changes[i].Kind)
+			require.Equal(producer.addr, changes[i].Actor)
+			require.Equal(expected.value, changes[i].Value)
+		}
+	}
+}
+
+func TestAssetMetadata(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer := newAccount(t)
+	n := newNetwork(t, 3, newGenesis(producer))
+
+	// Metadata must follow the schema
+	_, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: []byte("solar"),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputInvalidMetadata, result.Output)
+	metadata := &actions.AssetMetadata{
+		Symbol:      "SUN1",
+		Unit:        actions.UnitMWh,
+		Decimals:    3,
+		Source:      "solar",
+		Region:      "ERCOT",
+		Description: "rooftop arrays in Austin",
+	}
+	b, err := metadata.Marshal()
+	require.NoError(err)
+	unsupported := append([]byte{actions.AssetMetadataVersion + 1}, b[1:]...)
+	_, result = n.execute(1, producer, &actions.InitializeEnergyAsset{
+		Metadata: unsupported,
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputInvalidMetadata, result.Output)
+	_, result = n.execute(2, producer, &actions.InitializeEnergyAsset{
+		Metadata: append(b, 0),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputInvalidMetadata, result.Output)
+
+	// Wallets get the decoded metadata
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: b,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		exists, info, err := inst.cli.AssetMetadata(ctx, assetID)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(metadata, info)
+	}
+
+	// Updates are validated the same way
+	_, result = n.execute(1, producer, &actions.UpdateAssetMetadata{
+		Asset:    assetID,
+		Metadata: []byte("wind"),
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputInvalidMetadata, result.Output)
+	_, result = n.execute(2, producer, &actions.UpdateAssetMetadata{
+		Asset:    assetID,
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		_, info, err := inst.cli.AssetMetadata(ctx, assetID)
+		require.NoError(err)
+		require.Equal("WIND", info.Symbol)
+		require.Equal(actions.UnitKWh, info.Unit)
+		require.Equal("wind", info.Source)
+		require.Empty(info.Region)
+
+		// The native asset has no schema
+		exists, info, err := inst.cli.AssetMetadata(ctx, ids.Empty)
+		require.NoError(err)
+		require.True(exists)
+		require.Nil(info)
+	}
+}
+
+func TestRegisterSymbol(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newTrackingNetwork(t, 3, newGenesis(producer, consumer), &config.TrackedPair{Pair: "ETKN-SOLAR"})
+
+	solarID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	windID, result := n.execute(1, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "wind"),
+	})
+	require.True(result.Success)
+	for _, assetID := range []ids.ID{solarID, windID} {
+		_, result = n.execute(2, producer, &actions.ProduceEnergy{
+			To:    producer.pk,
+			Asset: assetID,
+			Value: 100,
+		})
+		require.True(result.Success)
+	}
+
+	// Only the owner can register a symbol and the native symbol is reserved
+	_, result = n.execute(0, consumer, &actions.RegisterSymbol{
+		Asset:  solarID,
+		Symbol: "SOLAR",
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputWrongOwner, result.Output)
+	_, result = n.execute(1, producer, &actions.RegisterSymbol{
+		Asset:  solarID,
+		Symbol: "ETKN",
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputSymbolTaken, result.Output)
+	symbolTx, result := n.execute(2, producer, &actions.RegisterSymbol{
+		Asset:  solarID,
+		Symbol: "SOLAR",
+	})
+	require.True(result.Success)
+
+	// Symbols are unique and an asset keeps its first one
+	_, result = n.execute(0, producer, &actions.RegisterSymbol{
+		Asset:  windID,
+		Symbol: "SOLAR",
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputSymbolTaken, result.Output)
+	_, result = n.execute(1, producer, &actions.RegisterSymbol{
+		Asset:  solarID,
+		Symbol: "SUN",
+	})
+	require.False(result.Success)
+	require.Equal(actions.OutputAssetHasSymbol, result.Output)
+	for _, inst := range n.instances {
+		exists, assetID, err := inst.cli.Symbol(ctx, "SOLAR")
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(solarID, assetID)
+		exists, symbol, err := inst.cli.AssetSymbol(ctx, solarID)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal("SOLAR", symbol)
+
+		exists, _, err = inst.cli.Symbol(ctx, "WIND")
+		require.NoError(err)
+		require.False(exists)
+		exists, _, err = inst.cli.AssetSymbol(ctx, windID)
+		require.NoError(err)
+		require.False(exists)
+
+		changes, err := inst.cli.AssetChanges(ctx, solarID)
+		require.NoError(err)
+		require.Len(changes, 1)
+		require.Equal(symbolTx, changes[0].TxID)
+		require.Equal(storage.AssetChangeSymbol, changes[0].Kind)
+		require.Equal([]byte("SOLAR"), changes[0].Value)
+	}
+
+	// The pair tracked by symbol is resolved once the symbol is registered
+	solarOrder, result := n.execute(2, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     solarID,
+		OutTick: 10,
+		Supply:  50,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  1_000,
+		Out:     windID,
+		OutTick: 10,
+		Supply:  50,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		for _, pair := range []string{"ETKN-SOLAR", actions.PairID(ids.Empty, solarID)} {
+			book, err := inst.cli.Orders(ctx, pair)
+			require.NoError(err)
+			require.Len(book.Asks, 1)
+			require.Equal(solarOrder, book.Asks[0].ID)
+		}
+		book, err := inst.cli.Orders(ctx, actions.PairID(ids.Empty, windID))
+		require.NoError(err)
+		require.Empty(book.Asks)
+
+		_, err = inst.cli.Orders(ctx, "ETKN-WIND")
+		require.ErrorContains(err, actions.ErrSymbolMissing.Error())
+	}
+}
+
+func TestRestartRebuildsLedger(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newTrackingNetwork(t, 2, newGenesis(producer, consumer), &config.TrackedPair{Pair: "ETKN-SOLAR"})
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.RegisterSymbol{
+		Asset:  assetID,
+		Symbol: "SOLAR",
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	// List asks and bids, then fill, amend and close some of them
+	ask := func(inTick uint64, supply uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			Side:    actions.SideAsk,
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: 10,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+	filled, amended, closed := ask(1_000, 30), ask(1_200, 20), ask(1_500, 20)
+	_, result = n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:      actions.SideBid,
+		In:        assetID,
+		InTick:    10,
+		Out:       ids.Empty,
+		OutTick:   900,
+		Supply:    4_500,
+		AllOrNone: true,
+	})
+	require.True(result.Success)
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: filled,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 1_000,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   amended,
+		Out:     assetID,
+		InTick:  1_100,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.CloseEnergyOrder{
+		Order: closed,
+		Out:   assetID,
+	})
+	require.True(result.Success)
+	before, err := n.cli(1).Orders(ctx, "ETKN-SOLAR")
+	require.NoError(err)
+	require.Len(before.Asks, 2)
+	require.Len(before.Bids, 1)
+
+	// The restarted node serves the same book before any new block
+	n.restart(1)
+	after, err := n.cli(1).Orders(ctx, "ETKN-SOLAR")
+	require.NoError(err)
+	require.Equal(before, after)
+
+	// and keeps it up to date afterwards
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: filled,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 2_000,
+	})
+	require.True(result.Success)
+	expected, err := n.cli(0).Orders(ctx, "ETKN-SOLAR")
+	require.NoError(err)
+	require.Len(expected.Asks, 1)
+	require.Equal(amended, expected.Asks[0].ID)
+	after, err = n.cli(1).Orders(ctx, "ETKN-SOLAR")
+	require.NoError(err)
+	require.Equal(expected, after)
+}
+
+func TestRebuildOpenOrders(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+	ask := func(inTick uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			Side:    actions.SideAsk,
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: 10,
+			Supply:  30,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+	filled, open := ask(1_000), ask(1_200)
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: filled,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 1_000,
+	})
+	require.True(result.Success)
+
+	// A node that has the orders in state but not in its metaDB rebuilds them
+	// from state on the next block
+	n.restartWithoutMetadata(1)
+	_, result = n.execute(0, producer, &actions.CreateEnergyOrder{
+		Side:    actions.SideAsk,
+		In:      ids.Empty,
+		InTick:  1_500,
+		Out:     assetID,
+		OutTick: 10,
+		Supply:  30,
+	})
+	require.True(result.Success)
+	pair := actions.PairID(ids.Empty, assetID)
+	expected, err := n.cli(0).Orders(ctx, pair)
+	require.NoError(err)
+	require.Len(expected.Asks, 3)
+	rebuilt, err := n.cli(1).Orders(ctx, pair)
+	require.NoError(err)
+	require.Len(rebuilt.Asks, len(expected.Asks))
+	for i, order := range expected.Asks {
+		require.Equal(order.ID, rebuilt.Asks[i].ID)
+		require.Equal(order.Remaining, rebuilt.Asks[i].Remaining)
+	}
+	require.Equal(filled, rebuilt.Asks[0].ID)
+	require.Equal(uint64(20), rebuilt.Asks[0].Remaining)
+	require.Equal(open, rebuilt.Asks[1].ID)
+
+	orders, _, err := n.cli(1).OrdersByOwner(ctx, producer.addr, ids.Empty, 0)
+	require.NoError(err)
+	require.Len(orders, 3)
+
+	// and keeps them up to date afterwards
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: filled,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 2_000,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 2)
+		require.Equal(open, book.Asks[0].ID)
+
+		orders, _, err := inst.cli.OrdersByOwner(ctx, producer.addr, ids.Empty, 0)
+		require.NoError(err)
+		require.Len(orders, 2)
+	}
+}
+
+func TestPriceTimePriority(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	ask := func(inTick uint64, outTick uint64, supply uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: outTick,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+
+	// These prices are equal as float64 but not as fractions
+	higher, lower := ask(1<<53+1, 1, 1), ask(1<<53, 1, 1)
+
+	// Equal prices are filled in the order they were listed
+	first, second, third := ask(1_000, 10, 10), ask(2_000, 20, 20), ask(1_000, 10, 30)
+	_, result = n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  10,
+		Out:     ids.Empty,
+		OutTick: 900,
+		Supply:  1_800,
+	})
+	require.True(result.Success)
+	pair := actions.PairID(ids.Empty, assetID)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Asks, 5)
+		for i, orderID := range []ids.ID{first, second, third, lower, higher} {
+			require.Equal(orderID, book.Asks[i].ID)
+		}
+		require.Len(book.Bids, 1)
+
+		depth, err := inst.cli.Depth(ctx, pair, 0)
+		require.NoError(err)
+		require.Equal([]*energyledger.Level{
+			{Tokens: 100, Energy: 1, Remaining: 60, Orders: 3},
+			{Tokens: 1 << 53, Energy: 1, Remaining: 1, Orders: 1},
+			{Tokens: 1<<53 + 1, Energy: 1, Remaining: 1, Orders: 1},
+		}, depth.Asks)
+		require.Equal([]*energyledger.Level{
+			{Tokens: 90, Energy: 1, Remaining: 1_800, Orders: 1},
+		}, depth.Bids)
+
+		depth, err = inst.cli.Depth(ctx, pair, 2)
+		require.NoError(err)
+		require.Len(depth.Asks, 2)
+	}
+
+	// An amend that keeps the rate and doesn't grow the order keeps its
+	// place, even if the ticks change
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   second,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		for i, orderID := range []ids.ID{first, second, third, lower, higher} {
+			require.Equal(orderID, book.Asks[i].ID)
+		}
+		orders, _, err := inst.cli.OrdersByOwner(ctx, producer.addr, ids.Empty, 0)
+		require.NoError(err)
+		for _, order := range orders {
+			if order.ID == second {
+				require.Equal(uint64(10), order.Remaining)
+			}
+		}
+	}
+
+	// Growing an order sends it behind the orders already at its price
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   first,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  20,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		for i, orderID := range []ids.ID{second, third, first, lower, higher} {
+			require.Equal(orderID, book.Asks[i].ID)
+		}
+	}
+
+	// So does repricing it, even back to the same price
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   second,
+		Out:     assetID,
+		InTick:  1_100,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.AmendEnergyOrder{
+		Order:   second,
+		Out:     assetID,
+		InTick:  1_000,
+		OutTick: 10,
+		Supply:  10,
+	})
+	require.True(result.Success)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		for i, orderID := range []ids.ID{third, first, second, lower, higher} {
+			require.Equal(orderID, book.Asks[i].ID)
+		}
+	}
+}
+
+func TestTrackedPairFilters(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newTrackingNetwork(t, 2, newGenesis(producer, consumer), &config.TrackedPair{
+		Pair:         "*",
+		MinRemaining: 10,
+		MinPrice:     50,
+		MaxPrice:     200,
+		MaxOrders:    3,
+	})
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	ask := func(inTick uint64, supply uint64) ids.ID {
+		orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+			In:      ids.Empty,
+			InTick:  inTick,
+			Out:     assetID,
+			OutTick: 1,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+		return orderID
+	}
+	pair := actions.PairID(ids.Empty, assetID)
+	requireAsks := func(orderIDs ...ids.ID) {
+		for _, inst := range n.instances {
+			book, err := inst.cli.Orders(ctx, pair)
+			require.NoError(err)
+			require.Len(book.Asks, len(orderIDs))
+			for i, orderID := range orderIDs {
+				require.Equal(orderID, book.Asks[i].ID)
+			}
+		}
+	}
+
+	// Orders that are too small or outside of the price band are never listed
+	small, pricey := ask(100, 5), ask(300, 10)
+	requireAsks()
+
+	cheap, expensive, middle := ask(100, 20), ask(150, 20), ask(120, 20)
+	requireAsks(cheap, middle, expensive)
+
+	// Going over the maximum drops the worst order of the longer side
+	bidID, result := n.execute(1, consumer, &actions.CreateEnergyOrder{
+		Side:    actions.SideBid,
+		In:      assetID,
+		InTick:  1,
+		Out:     ids.Empty,
+		OutTick: 90,
+		Supply:  1_800,
+	})
+	require.True(result.Success)
+	requireAsks(cheap, middle)
+	for _, inst := range n.instances {
+		book, err := inst.cli.Orders(ctx, pair)
+		require.NoError(err)
+		require.Len(book.Bids, 1)
+		require.Equal(bidID, book.Bids[0].ID)
+	}
+
+	// Orders are dropped once a fill leaves them below the minimum
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: cheap,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 1_500,
+	})
+	require.True(result.Success)
+	requireAsks(middle)
+
+	// Orders amended to pass the filters are listed again
+	amend := func(orderID ids.ID, inTick uint64, supply uint64) {
+		_, result := n.execute(0, producer, &actions.AmendEnergyOrder{
+			Order:   orderID,
+			Out:     assetID,
+			InTick:  inTick,
+			OutTick: 1,
+			Supply:  supply,
+		})
+		require.True(result.Success)
+	}
+	amend(pricey, 110, 10)
+	requireAsks(pricey, middle)
+	amend(small, 100, 10)
+	requireAsks(small, pricey)
+}
+
+func TestOrdersByOwner(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	// The index is kept for every pair, even if none is tracked
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newTrackingNetwork(t, 2, newGenesis(producer, consumer), &config.TrackedPair{Pair: "ETKN-SOLAR"})
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	orderIDs := make([]ids.ID, 3)
+	for i := range orderIDs {
+		orderIDs[i], result = n.execute(0, producer, &actions.CreateEnergyOrder{
+			In:      ids.Empty,
+			InTick:  100,
+			Out:     assetID,
+			OutTick: 1,
+			Supply:  20,
+		})
+		require.True(result.Success)
+	}
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: orderIDs[0],
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 500,
+	})
+	require.True(result.Success)
+	or, err := actions.UnmarshalOrderResult(result.Output)
+	require.NoError(err)
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: orderIDs[1],
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 2_000,
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.CloseEnergyOrder{
+		Order: orderIDs[2],
+		Out:   assetID,
+	})
+	require.True(result.Success)
+
+	// Only the partially filled order is still open
+	for _, inst := range n.instances {
+		orders, next, err := inst.cli.OrdersByOwner(ctx, producer.addr, ids.Empty, 0)
+		require.NoError(err)
+		require.Equal(ids.Empty, next)
+		require.Len(orders, 1)
+		require.Equal(orderIDs[0], orders[0].ID)
+		require.Equal(or.Remaining, orders[0].Remaining)
+		require.Equal(assetID, orders[0].Out)
+
+		orders, _, err = inst.cli.OrdersByOwner(ctx, consumer.addr, ids.Empty, 0)
+		require.NoError(err)
+		require.Empty(orders)
+	}
+
+	// Pages continue after the cursor
+	for i := range orderIDs {
+		orderIDs[i], result = n.execute(0, producer, &actions.CreateEnergyOrder{
+			In:      ids.Empty,
+			InTick:  100,
+			Out:     assetID,
+			OutTick: 1,
+			Supply:  10,
+		})
+		require.True(result.Success)
+	}
+	seen := map[ids.ID]bool{}
+	cursor := ids.Empty
+	for {
+		orders, next, err := n.instances[1].cli.OrdersByOwner(ctx, producer.addr, cursor, 1)
+		require.NoError(err)
+		require.Len(orders, 1)
+		seen[orders[0].ID] = true
+		if next == ids.Empty {
+			break
+		}
+		require.Equal(orders[0].ID, next)
+		cursor = next
+	}
+	require.Len(seen, 4)
+}
+
+func TestStreaming(t *testing.T) {
+	require := require.New(t)
+
+	producer, consumer := newAccount(t), newAccount(t)
+	n := newNetwork(t, 2, newGenesis(producer, consumer))
+
+	assetID, result := n.execute(0, producer, &actions.InitializeEnergyAsset{
+		Metadata: energyMetadata(t, "solar"),
+	})
+	require.True(result.Success)
+	_, result = n.execute(0, producer, &actions.ProduceEnergy{
+		To:    producer.pk,
+		Asset: assetID,
+		Value: 100,
+	})
+	require.True(result.Success)
+
+	cli, err := streaming.NewClient(n.instances[1].streamingURI)
+	require.NoError(err)
+	defer cli.Close()
+
+	// blockMessages returns the messages streamed for the next block
+	blockMessages := func() []*streaming.Message {
+		msgs := []*streaming.Message{}
+		for {
+			msg, err := cli.Listen()
+			require.NoError(err)
+			msgs = append(msgs, msg)
+			if msg.Kind == streaming.KindBlock {
+				return msgs
+			}
+		}
+	}
+
+	// Every subscription starts with a snapshot
+	require.NoError(cli.SubscribeBlocks())
+	msg, err := cli.Listen()
+	require.NoError(err)
+	require.Equal(streaming.KindSnapshot, msg.Kind)
+	require.Equal(streaming.StreamBlocks, msg.Stream)
+	require.NotNil(msg.Block)
+	height := msg.Height
+	require.Equal(height, msg.Block.Height)
+
+	pair := actions.PairID(ids.Empty, assetID)
+	require.NoError(cli.SubscribeOrders(pair))
+	msg, err = cli.Listen()
+	require.NoError(err)
+	require.Equal(streaming.KindSnapshot, msg.Kind)
+	require.Equal(pair, msg.Pair)
+	require.Empty(msg.Book.Bids)
+	require.Empty(msg.Book.Asks)
+
+	require.NoError(cli.SubscribeTxs(producer.addr))
+	msg, err = cli.Listen()
+	require.NoError(err)
+	require.Equal(streaming.KindSnapshot, msg.Kind)
+	require.Equal(producer.addr, msg.Address)
+	require.Empty(msg.Orders)
+
+	require.NoError(cli.Send(&streaming.Request{Method: streaming.MethodSubscribe, Stream: "trades"}))
+	msg, err = cli.Listen()
+	require.NoError(err)
+	require.Equal(streaming.KindError, msg.Kind)
+	require.Equal(streaming.ErrInvalidStream.Error(), msg.Error)
+
+	// Listing an order streams the tx, the new order and the block
+	orderID, result := n.execute(0, producer, &actions.CreateEnergyOrder{
+		In:      ids.Empty,
+		InTick:  100,
+		Out:     assetID,
+		OutTick: 1,
+		Supply:  20,
+	})
+	require.True(result.Success)
+	msgs := blockMessages()
+	require.Len(msgs, 3)
+	require.Equal(streaming.KindTx, msgs[0].Kind)
+	require.Equal(orderID, msgs[0].Tx.ID)
+	require.True(msgs[0].Tx.Success)
+	require.Equal(streaming.KindOrder, msgs[1].Kind)
+	require.Equal(energyledger.EventAdd, msgs[1].Order.Event)
+	require.Equal(orderID, msgs[1].Order.Order.ID)
+	require.Equal(uint64(20), msgs[1].Order.Order.Remaining)
+	require.Equal(height+1, msgs[2].Height)
+	require.Equal(height+1, msgs[2].Block.Height)
+	require.Equal(1, msgs[2].Block.Txs)
+
+	// Txs of other addresses are not streamed
+	_, result = n.execute(1, consumer, &actions.FillEnergyOrder{
+		Order: orderID,
+		Owner: producer.pk,
+		In:    ids.Empty,
+		Out:   assetID,
+		Value: 500,
+	})
+	require.True(result.Success)
+	msgs = blockMessages()
+	require.Len(msgs, 2)
+	require.Equal(energyledger.EventUpdate, msgs[0].Order.Event)
+	require.Equal(uint64(15), msgs[0].Order.Order.Remaining)
+
+	// A new subscription includes the order
+	require.NoError(cli.SubscribeTxs(producer.addr))
+	msg, err = cli.Listen()
+	require.NoError(err)
+	require.Equal(height+2, msg.Height)
+	require.Len(msg.Orders, 1)
+	require.Equal(orderID, msg.Orders[0].ID)
+
+	// Unsubscribed streams stop
+	require.NoError(cli.Send(&streaming.Request{
+		Method: streaming.MethodUnsubscribe,
+		Stream: streaming.StreamOrders,
+		Pair:   pair,
+	}))
+	msg, err = cli.Listen()
+	require.NoError(err)
+	require.Equal(streaming.KindUnsubscribed, msg.Kind)
+	_, result = n.execute(0, producer, &actions.CloseEnergyOrder{
+		Order: orderID,
+		Out:   assetID,
+	})
+	require.True(result.Success)
+	msgs = blockMessages()
+	require.Len(msgs, 2)
+	require.Equal(streaming.KindTx, msgs[0].Kind)
+}